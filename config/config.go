@@ -19,9 +19,80 @@ type Config struct {
 	Contract struct {
 		PrivateKey         string
 		NetUrl             string
+		WsUrl              string // 이벤트 구독용 websocket rpc url, 비어있으면 netUrl 연결로 구독
 		TransactionHash    string
 		TokenAddress       string
 		ConstructorAddress string
+		WaitConfirmations  uint64 // wait=true 전송시 기본 확인 블록 수
+		WaitTimeout        int    // wait=true 전송시 기본 대기 시간(초)
+
+		DynamicFee           bool    // EIP-1559 dynamic fee 트랜잭션 사용 여부
+		MaxFeePerGas         int64   // maxFeePerGas 상한(wei), 0이면 제한 없음
+		MaxPriorityFeePerGas int64   // maxPriorityFeePerGas 상한(wei), 0이면 제한 없음
+		MaxFeeMultiplier     float64 // maxFeePerGas = baseFee * multiplier + tip, 0이면 2
+
+		GasMargin   uint64 // EstimateGas 결과에 더할 여유분(%), 0이면 20
+		MaxGasLimit uint64 // gasLimit 최대값, 0이면 제한 없음. 컨트랙트 배포에는 적용하지 않음
+
+		LogRange uint64 // 전송 내역 조회시 FilterTransfer 한 번에 조회할 블록 수, 0이면 5000
+
+		StatePath string // 배포한 컨트랙트 주소와 배포 트랜잭션을 기록하는 파일, 비어있으면 기록하지 않음
+	}
+
+	// 기본 tokenAddress 외에 함께 사용할 토큰 목록
+	Tokens []struct {
+		Address string
+	}
+
+	// 실행 중에 등록, 제거한 토큰
+	Registry struct {
+		Path string // 실행 중에 등록, 제거한 토큰을 기록하는 파일, 비어있으면 메모리에만 보관
+	}
+
+	// 입금 알림 webhook 설정
+	Webhook struct {
+		Path          string // 등록한 webhook, 마지막으로 확인한 블록, 실패한 전송을 기록하는 파일, 비어있으면 메모리에만 보관
+		Confirmations uint64 // 입금 후 알림을 보내기까지 기다릴 확인 블록 수, 0이면 12
+		PollInterval  int    // 새 블록 확인 간격(초), 0이면 5
+		MaxAttempts   int    // 전송 실패시 최대 시도 횟수, 넘으면 dead letter로 이동. 0이면 5
+		Timeout       int    // 전송 한 번의 제한 시간(초), 0이면 10
+	}
+
+	// 토큰 이벤트 로컬 색인 설정
+	Indexer struct {
+		Enabled      bool   // 색인 사용 여부
+		Path         string // 색인 저장소(leveldb) 경로
+		StartBlock   uint64 // 처음 색인을 시작할 블록, 토큰 배포 블록으로 설정
+		PollInterval int    // 새 블록 확인 간격(초), 0이면 5
+	}
+
+	// 서비스 지갑 전송 한도 설정, 토큰(또는 coin)별로 rules에 지정
+	Policy struct {
+		Path  string // 최근 24시간 전송 기록을 보관하는 파일, 비어있으면 메모리에만 보관
+		Rules []struct {
+			Token               string // 토큰 주소 또는 심볼, coin은 WEMIX 코인, *는 rule이 없는 모든 토큰
+			MaxPerTransfer      string // 한 번에 보낼 수 있는 최대 전송량(소수 문자열), 비어있으면 제한 없음
+			DailyPerKey         string // API key별 최근 24시간 전송량 한도
+			DailyPerDestination string // 받는 주소별 최근 24시간 전송량 한도
+			DailyTotal          string // 서비스 지갑 전체 최근 24시간 전송량 한도
+		}
+	}
+
+	// 서비스 지갑 전송 대상 주소 제한, allow, deny 목록은 실행 중에 /v1/addresses로 관리
+	AddressList struct {
+		Path          string   // 실행 중에 등록한 allow, deny 목록을 기록하는 파일, 비어있으면 메모리에만 보관
+		AllowlistOnly bool     // true면 allow 목록에 있는 주소로만 전송, 운영 treasury 지갑에 사용
+		Deny          []string // 항상 차단할 주소, 실행 중에는 제거할 수 없음
+	}
+
+	// API key 인증 설정
+	Auth struct {
+		Enabled bool   // API key 인증 사용 여부, false면 모든 요청 허용
+		Path    string // 발급한 API key의 hash와 scope를 기록하는 파일
+
+		SignedRequests bool   // true면 POST, DELETE 요청에 secret으로 만든 HMAC 서명 필요
+		SigningSecret  string // 요청 서명에 사용할 공유 secret
+		MaxSkew        int    // 서명 timestamp 허용 오차(초), 0이면 300
 	}
 
 	KeyStore struct {
@@ -54,7 +125,6 @@ func NewConfig(fpath string) (*Config, error) {
 					pData := crypto.FromECDSA(account.PrivateKey)
 					// Encode시 0x가 접두어로 붙기때문에 제거
 					c.Contract.PrivateKey = hexutil.Encode(pData)[2:]
-					fmt.Println(c)
 					return c, nil
 				}
 			}
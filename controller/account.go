@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// address 헤더 주소의 WEMIX 잔액, pending/confirmed nonce, 컨트랙트 여부, token 잔액을 한 번에 조회
+func (p *Controller) SearchAccountController(c *gin.Context) {
+	address := c.GetHeader("address")
+	if address == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "address 정보가 유효하지 않습니다",
+		})
+		return
+	}
+
+	account, err := p.md.SearchAccountModel(c.Query("token"), address)
+
+	if err != nil {
+		abortSearchError(c, "계정 정보를 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, account)
+}
@@ -0,0 +1,53 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSearchAccountController(t *testing.T) {
+	e := newTestEnv(t)
+
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": newAddress().Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	account := func(address string) md.AccountInfo {
+		t.Helper()
+		w := e.request("GET", "/v1/account", map[string]string{"address": address})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		var res md.AccountInfo
+		e.decode(w, &res)
+		return res
+	}
+
+	// 블록에 포함되기 전에는 pending nonce만 증가
+	res := account(owner.Hex())
+	if res.PendingNonce != 2 || res.ConfirmedNonce != 1 || res.PendingCount != 1 || res.IsContract {
+		t.Errorf("pending account = %+v", res)
+	}
+	if res.Token != e.token.Hex() || res.TokenBalance != ether("1000000000000000000000000000").String() {
+		t.Errorf("token balance = %+v", res)
+	}
+	e.sim.Commit()
+	res = account(owner.Hex())
+	if res.ConfirmedNonce != 2 || res.PendingCount != 0 || res.BlockNumber != 2 || res.Balance != e.coinBalance(owner).String() {
+		t.Errorf("mined account = %+v", res)
+	}
+
+	if res := account(e.token.Hex()); !res.IsContract || res.TokenBalance != "0" {
+		t.Errorf("contract account = %+v", res)
+	}
+	if w := e.request("GET", "/v1/account", map[string]string{"address": "0x1234"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid address status = %d, want 400", w.Code)
+	}
+	if w := e.request("GET", "/v1/account", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("missing address status = %d, want 400", w.Code)
+	}
+}
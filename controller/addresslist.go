@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allow 또는 deny 목록에 주소 등록 요청
+type AddAddressRuleRequest struct {
+	Address string `json:"address" binding:"required"`
+	List    string `json:"list" binding:"required"`
+	Note    string `json:"note"`
+}
+
+// 주소 목록 요청 실패 응답
+func abortAddressRuleError(c *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, model.ErrUnknownAddressRule) {
+		status, message = http.StatusNotFound, "목록에 없는 주소입니다!"
+	} else if errors.Is(err, model.ErrInvalidAddressList) {
+		message = "list 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrInvalidAddress) {
+		message = "address 정보가 유효하지 않습니다"
+	} else {
+		status = http.StatusInternalServerError
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// allowlistOnly 여부와 allow, deny 목록, config의 deny 목록도 source가 config로 포함
+func (p *Controller) ListAddressRulesController(c *gin.Context) {
+	c.JSON(200, p.md.ListAddressRulesModel())
+}
+
+func (p *Controller) AddAddressRuleController(c *gin.Context) {
+	var body AddAddressRuleRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	rule, err := p.md.AddAddressRuleModel(body.List, body.Address, body.Note)
+
+	if err != nil {
+		abortAddressRuleError(c, "주소를 등록하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, rule)
+}
+
+func (p *Controller) RemoveAddressRuleController(c *gin.Context) {
+	rule, err := p.md.RemoveAddressRuleModel(c.Param("list"), c.Param("address"))
+
+	if err != nil {
+		abortAddressRuleError(c, "주소를 삭제하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, rule)
+}
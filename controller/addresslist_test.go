@@ -0,0 +1,129 @@
+package controller_test
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	conf "go-contract/config"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestAddressListController(t *testing.T) {
+	bad := newAddress()
+	path := filepath.Join(t.TempDir(), "addresslist.json")
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.AddressList.Path = path
+		cfg.AddressList.Deny = []string{bad.Hex()}
+	})
+
+	for name, c := range map[string]struct {
+		path    string
+		address string
+		status  int
+		want    string
+	}{
+		"zero":         {path: "/v1/coin/", address: common.Address{}.Hex(), status: http.StatusForbidden, want: md.BlockZeroAddress},
+		"token":        {path: "/v1/token/", address: e.token.Hex(), status: http.StatusForbidden, want: md.BlockTokenContract},
+		"coin token":   {path: "/v1/coin/", address: e.token.Hex(), status: http.StatusForbidden, want: md.BlockTokenContract},
+		"config deny":  {path: "/v1/token/", address: bad.Hex(), status: http.StatusForbidden, want: md.BlockDenylist},
+		"short":        {path: "/v1/token/", address: "0x1234", status: http.StatusBadRequest, want: "invalid address"},
+		"not hex coin": {path: "/v1/coin/", address: "hello", status: http.StatusBadRequest, want: "invalid address"},
+	} {
+		w := e.request("POST", c.path, map[string]string{"address": c.address, "amount": "1"})
+		if w.Code != c.status || !strings.Contains(w.Body.String(), c.want) {
+			t.Errorf("%s status = %d, body: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	// approve의 spender와 transferFrom의 받는 주소도 같은 규칙으로 차단
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: bad.Hex(), Amount: "1"}); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), md.BlockDenylist) {
+		t.Errorf("approve status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: owner.Hex(), Amount: "5"}); w.Code != http.StatusOK {
+		t.Fatalf("approve self status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	if w := e.requestJSON("POST", "/v1/token/transferFrom", ctl.TransferFromRequest{From: owner.Hex(), To: bad.Hex(), Amount: "5"}); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), md.BlockDenylist) {
+		t.Errorf("transferFrom status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if e.tokenBalance(bad).Sign() != 0 {
+		t.Errorf("denied balance = %s, want 0", e.tokenBalance(bad))
+	}
+
+	// 실행 중에 deny 목록에 등록하면 바로 차단, 삭제하면 다시 전송
+	to := newAddress()
+	if w := e.requestJSON("POST", "/v1/addresses/", ctl.AddAddressRuleRequest{Address: strings.ToLower(to.Hex()), List: md.AddressListDeny, Note: "phishing"}); w.Code != http.StatusOK {
+		t.Fatalf("add status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusForbidden {
+		t.Errorf("runtime deny status = %d, body: %s", w.Code, w.Body.String())
+	}
+	w := e.request("GET", "/v1/addresses/", nil)
+	var lists md.AddressLists
+	e.decode(w, &lists)
+	if len(lists.Deny) != 2 || lists.Deny[0].Source != "config" || lists.Deny[1].Address != to.Hex() || lists.Deny[1].Note != "phishing" || len(lists.Allow) != 0 {
+		t.Errorf("lists = %s", w.Body.String())
+	}
+	if w := e.request("DELETE", "/v1/addresses/deny/"+bad.Hex(), nil); w.Code != http.StatusNotFound {
+		t.Errorf("remove config deny status = %d, want 404", w.Code)
+	}
+	if w := e.request("DELETE", "/v1/addresses/deny/"+to.Hex(), nil); w.Code != http.StatusOK {
+		t.Fatalf("remove status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Errorf("removed deny status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestJSON("POST", "/v1/addresses/", ctl.AddAddressRuleRequest{Address: to.Hex(), List: "block"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid list status = %d, want 400", w.Code)
+	}
+}
+
+func TestAllowlistOnlyController(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addresslist.json")
+	treasury := newAddress()
+	opt := func(cfg *conf.Config) {
+		cfg.AddressList.Path = path
+		cfg.AddressList.AllowlistOnly = true
+	}
+	e := newTestEnv(t, opt)
+
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": treasury.Hex(), "amount": "1"}); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), md.BlockNotAllowed) {
+		t.Errorf("not allowed status = %d, body: %s", w.Code, w.Body.String())
+	}
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: treasury.Hex(), Amount: "1"}); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), md.BlockNotAllowed) {
+		t.Errorf("approve not allowed status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestJSON("POST", "/v1/token/transferFrom", ctl.TransferFromRequest{From: owner.Hex(), To: treasury.Hex(), Amount: "1"}); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), md.BlockNotAllowed) {
+		t.Errorf("transferFrom not allowed status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestJSON("POST", "/v1/addresses/", ctl.AddAddressRuleRequest{Address: treasury.Hex(), List: md.AddressListAllow}); w.Code != http.StatusOK {
+		t.Fatalf("add status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": treasury.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Errorf("allowed status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// allow 목록에 있어도 deny 목록이 우선
+	if w := e.requestJSON("POST", "/v1/addresses/", ctl.AddAddressRuleRequest{Address: treasury.Hex(), List: md.AddressListDeny}); w.Code != http.StatusOK {
+		t.Fatalf("add deny status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": treasury.Hex(), "amount": "1"}); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), md.BlockDenylist) {
+		t.Errorf("deny over allow status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// 재시작해도 실행 중에 등록한 목록 유지
+	w := newTestEnv(t, opt).request("GET", "/v1/addresses/", nil)
+	var lists md.AddressLists
+	e.decode(w, &lists)
+	if !lists.AllowlistOnly || len(lists.Allow) != 1 || len(lists.Deny) != 1 {
+		t.Errorf("reloaded lists = %s", w.Body.String())
+	}
+}
@@ -0,0 +1,224 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"go-contract/model"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 인증한 API key를 gin.Context에 저장하는 키
+const apiKeyContextKey = "apiKey"
+
+// 서명을 확인하기 위해 읽는 요청 body 최대 크기
+const maxSignedBodyBytes = 1 << 20
+
+// API key 발급 요청, scopes는 token:read, token:send, coin:read, coin:send, admin 중 선택
+type IssueAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+}
+
+type APIKeyListResponse struct {
+	Keys []model.APIKey `json:"keys"`
+}
+
+// Authorization: Bearer 헤더 또는 X-API-Key 헤더의 API key
+func apiKeyFromHeader(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+			return strings.TrimSpace(auth[7:])
+		}
+		return ""
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+// 인증 실패 응답, key가 없거나 유효하지 않으면 401, scope가 부족하면 403
+func abortAuthError(c *gin.Context, status int, message string, err error) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+// 인증한 API key, 인증을 사용하지 않으면 nil
+func apiKeyFromContext(c *gin.Context) *model.APIKey {
+	if v, ok := c.Get(apiKeyContextKey); ok {
+		return v.(*model.APIKey)
+	}
+	return nil
+}
+
+// 인증한 API key id, 전송 한도를 API key별로 확인할 때 사용
+func apiKeyID(c *gin.Context) string {
+	if key := apiKeyFromContext(c); key != nil {
+		return key.ID
+	}
+	return ""
+}
+
+// API key 인증 미들웨어, config의 auth.enabled가 false면 모든 요청 허용
+func (p *Controller) Authenticate() gin.HandlerFunc {
+	return p.authenticate(false)
+}
+
+// 이벤트 구독 인증 미들웨어, 헤더의 API key 또는 ticket 쿼리로 받은 구독 ticket 확인
+// 브라우저 EventSource, WebSocket은 헤더를 설정할 수 없으므로 POST /v1/token/events/ticket 으로 받은 ticket 사용
+func (p *Controller) AuthenticateStream() gin.HandlerFunc {
+	return p.authenticate(true)
+}
+
+func (p *Controller) authenticate(allowTicket bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.md.AuthEnabled() {
+			c.Next()
+			return
+		}
+		raw := apiKeyFromHeader(c)
+		ticket := c.Query("ticket")
+		if raw == "" && (!allowTicket || ticket == "") {
+			abortAuthError(c, http.StatusUnauthorized, "API key가 필요합니다", model.ErrInvalidAPIKey)
+			return
+		}
+		var key *model.APIKey
+		var err error
+		if raw != "" {
+			key, err = p.md.AuthenticateModel(raw)
+		} else {
+			key, err = p.md.AuthenticateStreamTicketModel(ticket)
+		}
+		if errors.Is(err, model.ErrInvalidAPIKey) {
+			abortAuthError(c, http.StatusUnauthorized, "API key가 유효하지 않습니다", err)
+			return
+		} else if err != nil {
+			abortAuthError(c, http.StatusInternalServerError, "API key를 확인하지 못했습니다!", err)
+			return
+		}
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// scopes 중 하나라도 가진 API key만 허용, 인증을 사용하지 않으면 모든 요청 허용
+func (p *Controller) RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.md.AuthEnabled() {
+			c.Next()
+			return
+		}
+		key := apiKeyFromContext(c)
+		if key == nil {
+			abortAuthError(c, http.StatusUnauthorized, "API key가 필요합니다", model.ErrInvalidAPIKey)
+			return
+		}
+		for _, scope := range scopes {
+			if key.HasScope(scope) {
+				c.Next()
+				return
+			}
+		}
+		abortAuthError(c, http.StatusForbidden, "권한이 없습니다", errors.New("required scope: "+strings.Join(scopes, " or ")))
+	}
+}
+
+// 서명된 요청 확인 미들웨어, config의 auth.signedRequests가 true면 POST, DELETE 요청에 서명 필요
+// 쿼리를 포함한 path와 body를 서명하므로 같은 요청을 다시 보내거나 내용을 바꾸면 401, body가 1MB보다 크면 413
+func (p *Controller) VerifySignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.md.SignedRequestsEnabled() || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBodyBytes)); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					abortAuthError(c, http.StatusRequestEntityTooLarge, "요청 body가 너무 큽니다", err)
+					return
+				}
+				abortAuthError(c, http.StatusBadRequest, "요청 정보가 유효하지 않습니다", err)
+				return
+			}
+			// 뒤의 controller가 다시 읽을 수 있도록 되돌림
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		err := p.md.VerifyRequestModel(
+			c.Request.Method,
+			c.Request.URL.RequestURI(),
+			body,
+			c.GetHeader(model.RequestTimestampHeader),
+			c.GetHeader(model.RequestNonceHeader),
+			c.GetHeader(model.RequestSignatureHeader),
+		)
+		if errors.Is(err, model.ErrStaleTimestamp) {
+			abortAuthError(c, http.StatusUnauthorized, "요청 timestamp가 유효하지 않습니다", err)
+			return
+		} else if errors.Is(err, model.ErrReplayedNonce) {
+			abortAuthError(c, http.StatusUnauthorized, "이미 처리한 요청입니다", err)
+			return
+		} else if err != nil {
+			abortAuthError(c, http.StatusUnauthorized, "요청 서명이 유효하지 않습니다", err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// API key 관리 실패 응답
+func abortAPIKeyError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, model.ErrUnknownAPIKey) {
+		status, message = http.StatusNotFound, "API key를 찾을 수 없습니다!"
+	} else if errors.Is(err, model.ErrInvalidScope) {
+		status, message = http.StatusBadRequest, "scopes 정보가 유효하지 않습니다"
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func (p *Controller) ListAPIKeysController(c *gin.Context) {
+	keys, err := p.md.ListAPIKeysModel()
+
+	if err != nil {
+		abortAPIKeyError(c, "API key 목록을 조회하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, APIKeyListResponse{Keys: keys})
+}
+
+// API key 발급, key는 발급 응답에서 한 번만 확인할 수 있음
+func (p *Controller) IssueAPIKeyController(c *gin.Context) {
+	var body IssueAPIKeyRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	issued, err := p.md.IssueAPIKeyModel(body.Name, body.Scopes)
+
+	if err != nil {
+		abortAPIKeyError(c, "API key를 발급하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, issued)
+}
+
+func (p *Controller) RevokeAPIKeyController(c *gin.Context) {
+	key, err := p.md.RevokeAPIKeyModel(c.Param("id"))
+
+	if err != nil {
+		abortAPIKeyError(c, "API key를 폐기하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, key)
+}
@@ -0,0 +1,181 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+)
+
+// API key를 Authorization 헤더로 보내는 요청, body가 nil이 아니면 json으로 전송
+func (e *testEnv) requestWithKey(method string, path string, key string, headers map[string]string, body interface{}) *httptest.ResponseRecorder {
+	e.t.Helper()
+	var req *http.Request
+	if body != nil {
+		data, _ := json.Marshal(body)
+		req = httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	e.engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyAuthController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.Path = filepath.Join(t.TempDir(), "apikeys.json")
+	})
+	// keygen 명령처럼 서버 밖에서 발급한 key도 바로 사용
+	admin, err := md.IssueAPIKey(e.cfg, "admin", []string{md.ScopeAdmin})
+	if err != nil {
+		t.Fatalf("IssueAPIKey Error: %s", err)
+	}
+
+	for name, key := range map[string]string{"missing": "", "malformed": "abc", "wrong secret": admin.ID + ".00"} {
+		w := e.requestWithKey("GET", "/v1/token/name", key, nil, nil)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), `"message"`) || !strings.Contains(w.Body.String(), "invalid api key") {
+			t.Errorf("%s status = %d, body: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	if w := e.requestWithKey("POST", "/v1/apikeys/", admin.Key, nil, ctl.IssueAPIKeyRequest{Name: "bad", Scopes: []string{"token:all"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid scope status = %d, body: %s", w.Code, w.Body.String())
+	}
+	w := e.requestWithKey("POST", "/v1/apikeys/", admin.Key, nil, ctl.IssueAPIKeyRequest{Name: "reader", Scopes: []string{md.ScopeTokenRead}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("issue status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var reader md.IssuedAPIKey
+	e.decode(w, &reader)
+	if reader.Key == "" || reader.Hash != "" || len(reader.Scopes) != 1 {
+		t.Errorf("issued = %+v", reader)
+	}
+	if w := e.requestWithKey("GET", "/v1/token/name", reader.Key, nil, nil); w.Code != http.StatusOK {
+		t.Errorf("read status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestWithKey("GET", "/v1/token/name", "", map[string]string{"X-API-Key": reader.Key}, nil); w.Code != http.StatusOK {
+		t.Errorf("X-API-Key status = %d, body: %s", w.Code, w.Body.String())
+	}
+	// scope가 맞지 않으면 403
+	for _, path := range []string{"/v1/token/", "/v1/coin/"} {
+		w := e.requestWithKey("POST", path, reader.Key, map[string]string{"address": newAddress().Hex(), "amount": "1"}, nil)
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "required scope") {
+			t.Errorf("%s status = %d, body: %s", path, w.Code, w.Body.String())
+		}
+	}
+	if w := e.requestWithKey("GET", "/v1/apikeys/", reader.Key, nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("list with reader status = %d, want 403", w.Code)
+	}
+
+	// 목록에는 hash를 포함하지 않음
+	w = e.requestWithKey("GET", "/v1/apikeys/", admin.Key, nil, nil)
+	var list ctl.APIKeyListResponse
+	e.decode(w, &list)
+	if len(list.Keys) != 2 || strings.Contains(w.Body.String(), "hash") {
+		t.Errorf("list = %s", w.Body.String())
+	}
+
+	// 폐기한 key는 401
+	if w := e.requestWithKey("DELETE", "/v1/apikeys/"+reader.ID, admin.Key, nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestWithKey("GET", "/v1/token/name", reader.Key, nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked status = %d, want 401", w.Code)
+	}
+	if w := e.requestWithKey("DELETE", "/v1/apikeys/unknown", admin.Key, nil, nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown revoke status = %d, want 404", w.Code)
+	}
+}
+
+// secret으로 서명한 POST 요청
+func (e *testEnv) signedRequest(path string, headers map[string]string, timestamp int64, nonce string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	ts := strconv.FormatInt(timestamp, 10)
+	signed := map[string]string{
+		md.RequestTimestampHeader: ts,
+		md.RequestNonceHeader:     nonce,
+		md.RequestSignatureHeader: md.SignRequest(e.cfg.Auth.SigningSecret, "POST", path, nil, ts, nonce),
+	}
+	for k, v := range headers {
+		signed[k] = v
+	}
+	return e.request("POST", path, signed)
+}
+
+func TestSignedRequestController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Auth.SignedRequests = true
+		cfg.Auth.SigningSecret = "payout-secret"
+		cfg.Auth.MaxSkew = 60
+	})
+	to := newAddress()
+	headers := map[string]string{"address": to.Hex(), "amount": "1"}
+	now := time.Now().Unix()
+
+	if w := e.signedRequest("/v1/token/", headers, now, "n1"); w.Code != http.StatusOK {
+		t.Fatalf("signed status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	// 캡처한 요청을 그대로 다시 보내면 거부
+	if w := e.signedRequest("/v1/token/", headers, now, "n1"); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "replayed") {
+		t.Errorf("replay status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.signedRequest("/v1/token/", headers, now-120, "n2"); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "stale") {
+		t.Errorf("stale status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("POST", "/v1/token/", headers); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "invalid request signature") {
+		t.Errorf("unsigned status = %d, body: %s", w.Code, w.Body.String())
+	}
+	// nonce가 다르면 같은 내용도 새 요청으로 처리
+	if w := e.signedRequest("/v1/token/", headers, now, "n3"); w.Code != http.StatusOK {
+		t.Fatalf("second signed status = %d, body: %s", w.Code, w.Body.String())
+	}
+	// 서명한 path와 다른 쿼리를 붙이면 서명 불일치
+	ts := strconv.FormatInt(now, 10)
+	tampered := map[string]string{
+		"address":                 to.Hex(),
+		"amount":                  "1",
+		md.RequestTimestampHeader: ts,
+		md.RequestNonceHeader:     "n4",
+		md.RequestSignatureHeader: md.SignRequest("payout-secret", "POST", "/v1/token/", nil, ts, "n4"),
+	}
+	if w := e.request("POST", "/v1/token/?token="+e.token.Hex(), tampered); w.Code != http.StatusUnauthorized {
+		t.Errorf("tampered status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	if e.tokenBalance(to).Cmp(ether("2000000000000000000")) != 0 {
+		t.Errorf("balance = %s, want 2", e.tokenBalance(to))
+	}
+
+	// 서명을 확인하기 전에 큰 body는 거부
+	big := httptest.NewRequest("POST", "/v1/token/", bytes.NewReader(make([]byte, 2<<20)))
+	w := httptest.NewRecorder()
+	e.engine.ServeHTTP(w, big)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// 조회 요청은 서명 없이 허용
+	if w := e.request("GET", "/v1/token/name", nil); w.Code != http.StatusOK {
+		t.Errorf("get status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// block, timestamp 쿼리로 조회 시점 지정, 둘 다 없으면 최신 상태
+func blockAtQuery(c *gin.Context) (*model.BlockAt, bool) {
+	at := &model.BlockAt{}
+	var ok bool
+	if at.Block, ok = blockQuery(c, "block"); !ok {
+		return nil, false
+	}
+	if at.Timestamp, ok = blockQuery(c, "timestamp"); !ok {
+		return nil, false
+	}
+	return at, true
+}
+
+// 잔액 조회 실패 응답
+func abortBalanceError(c *gin.Context, err error) {
+	if errors.Is(err, model.ErrInvalidBlockAt) {
+		abortSearchError(c, "block과 timestamp는 함께 지정할 수 없습니다", err)
+	} else if errors.Is(err, model.ErrBlockNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"message": "조회할 블록이 없습니다",
+			"error":   err.Error(),
+		})
+	} else {
+		abortSearchError(c, "balance를 가져오지 못했습니다!", err)
+	}
+}
+
+// address의 WEMIX 코인 잔액(wei), block 또는 timestamp로 과거 시점 조회
+func (p *Controller) SearchCoinBalanceController(c *gin.Context) {
+	address := c.GetHeader("address")
+	if address == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "address 정보가 유효하지 않습니다",
+		})
+		return
+	}
+	at, ok := blockAtQuery(c)
+	if !ok {
+		return
+	}
+
+	balance, err := p.md.SearchCoinBalanceModel(address, at)
+
+	if err != nil {
+		abortBalanceError(c, err)
+		return
+	}
+
+	c.JSON(200, balance)
+}
@@ -0,0 +1,84 @@
+package controller_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSearchCoinBalanceController(t *testing.T) {
+	e := newTestEnv(t)
+
+	to := newAddress()
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "0.5"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	e.sim.Commit()
+	sent := e.sim.Blockchain().GetHeaderByNumber(2)
+	half := ether("500000000000000000")
+
+	balanceOf := func(query string) md.Balance {
+		t.Helper()
+		w := e.request("GET", "/v1/coin/balance"+query, map[string]string{"address": to.Hex()})
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, body: %s", query, w.Code, w.Body.String())
+		}
+		var res md.Balance
+		e.decode(w, &res)
+		return res
+	}
+	if res := balanceOf(""); res.Balance.Cmp(half) != 0 || res.BlockNumber != nil {
+		t.Errorf("latest = %+v", res)
+	}
+	if res := balanceOf("?block=1"); res.Balance.Sign() != 0 || *res.BlockNumber != 1 {
+		t.Errorf("block 1 = %+v", res)
+	}
+	// 블록 시각 사이의 timestamp는 그 이전 블록으로 조회
+	for _, ts := range []uint64{sent.Time, sent.Time + 5} {
+		res := balanceOf("?timestamp=" + strconv.FormatUint(ts, 10))
+		if res.Balance.Cmp(half) != 0 || *res.BlockNumber != 2 || *res.Timestamp != sent.Time {
+			t.Errorf("timestamp %d = %+v", ts, res)
+		}
+	}
+	if res := balanceOf("?timestamp=" + strconv.FormatUint(sent.Time-1, 10)); res.Balance.Sign() != 0 || *res.BlockNumber != 1 {
+		t.Errorf("timestamp before send = %+v", res)
+	}
+
+	head := e.sim.Blockchain().CurrentHeader()
+	for query, code := range map[string]int{
+		"?block=" + strconv.FormatUint(head.Number.Uint64()+1, 10): http.StatusNotFound,
+		"?timestamp=" + strconv.FormatUint(head.Time+1, 10):        http.StatusNotFound,
+		"?block=1&timestamp=1":                                     http.StatusBadRequest,
+		"?block=latest":                                            http.StatusBadRequest,
+	} {
+		if w := e.request("GET", "/v1/coin/balance"+query, map[string]string{"address": to.Hex()}); w.Code != code {
+			t.Errorf("%s status = %d, want %d", query, w.Code, code)
+		}
+	}
+	if w := e.request("GET", "/v1/coin/balance", map[string]string{"address": "0x1234"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid address status = %d, want 400", w.Code)
+	}
+}
+
+func TestSearchTokenBalanceAtBlockController(t *testing.T) {
+	e := newTestEnv(t)
+	e.sim.Commit()
+
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	head := e.sim.Blockchain().CurrentHeader()
+	w := e.request("GET", "/v1/token/balance?timestamp="+strconv.FormatUint(head.Time, 10), map[string]string{"address": owner.Hex()})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.Balance
+	e.decode(w, &res)
+	want, _ := e.caller.BalanceOf(nil, owner)
+	if res.Balance.Cmp(want) != 0 || *res.BlockNumber != head.Number.Uint64() {
+		t.Errorf("balance = %+v, want %s at %d", res, want, head.Number)
+	}
+}
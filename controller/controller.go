@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"go-contract/model"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,66 +25,176 @@ func (p *Controller) GetOK(c *gin.Context) {
 	return
 }
 
-func (p *Controller) SearchTokenSymbolByTokenNameController(c *gin.Context) {
-	tokenName := c.Query("tokenName")
-	symbol, err := p.md.SearchTokenSymbolByTokenNameModel(tokenName)
-
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "symbol을 가져오지 못했습니다!",
-			"error":   err.Error(),
-		})
-		return
+// 전송 요청 정보를 헤더와 쿼리에서 읽어옴
+// wait=true면 confirmations, timeout(초) 쿼리로 영수증 대기 조건을 지정할 수 있음
+func sendRequestFromContext(c *gin.Context) (*model.SendRequest, bool) {
+	req, ok := transferRequestFromContext(c)
+	if !ok {
+		return nil, false
+	}
+	if !applyWaitOption(c, req) {
+		return nil, false
 	}
 
-	c.JSON(200, gin.H{"symbol": symbol})
+	return req, true
 }
 
-func (p *Controller) SearchTokenBalanceByAddressController(c *gin.Context) {
-
+// 받는 주소와 전송량을 헤더에서 읽어옴
+// amount는 unit이 base면 최소 단위 정수, 비어있거나 decimal이면 소수 문자열로 해석
+func transferRequestFromContext(c *gin.Context) (*model.SendRequest, bool) {
 	address := c.GetHeader("address")
 	if address == "" {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"message": "address 정보가 유효하지 않습니다",
 		})
-		return
+		return nil, false
 	}
-
-	balance, err := p.md.SearchTokenBalanceByAddressModel(address)
-
-	if err != nil {
+	amount := c.GetHeader("amount")
+	if amount == "" {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "balance를 가져오지 못했습니다!",
-			"error":   err.Error(),
+			"message": "amount 정보가 유효하지 않습니다",
 		})
-		return
+		return nil, false
+	}
+	req := &model.SendRequest{TargetAddress: address, Amount: amount}
+	req.APIKey = apiKeyID(c)
+	if !applyUnit(c, req, c.GetHeader("unit")) {
+		return nil, false
 	}
 
-	c.JSON(200, gin.H{"balance": balance})
+	return req, true
 }
 
-func (p *Controller) SendTokenByAddressController(c *gin.Context) {
-	address := c.GetHeader("address")
-	if address == "" {
+// unit이 base면 amount를 최소 단위 정수, 비어있거나 decimal이면 소수 문자열로 해석
+func applyUnit(c *gin.Context, req *model.SendRequest, unit string) bool {
+	switch unit {
+	case "", "decimal":
+	case "base":
+		req.BaseUnit = true
+	default:
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "address 정보가 유효하지 않습니다",
+			"message": "unit 정보가 유효하지 않습니다",
+			"error":   "unit은 base 또는 decimal 이어야 합니다",
 		})
-		return
+		return false
 	}
-	err := p.md.SendTokenByAddressModel(address, "")
+	return true
+}
 
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "전송에 실패했습니다!",
+// wait=true 쿼리면 confirmations, timeout(초) 쿼리로 영수증 대기 조건 지정
+func applyWaitOption(c *gin.Context, req *model.SendRequest) bool {
+	if wait, _ := strconv.ParseBool(c.DefaultQuery("wait", "false")); !wait {
+		return true
+	}
+	opt, ok := waitOptionFromQuery(c)
+	req.Wait = opt
+	return ok
+}
+
+// confirmations, timeout(초) 쿼리로 영수증 대기 조건을 읽어옴, 생략한 값은 config 기본값 사용
+func waitOptionFromQuery(c *gin.Context) (*model.WaitOption, bool) {
+	opt := &model.WaitOption{}
+	if v := c.Query("confirmations"); v != "" {
+		confirmations, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || confirmations == 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": "confirmations 정보가 유효하지 않습니다",
+			})
+			return nil, false
+		}
+		opt.Confirmations = confirmations
+	}
+	if v := c.Query("timeout"); v != "" {
+		timeout, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || timeout == 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": "timeout 정보가 유효하지 않습니다",
+			})
+			return nil, false
+		}
+		opt.Timeout = time.Duration(timeout) * time.Second
+	}
+	return opt, true
+}
+
+// 전송 실패 응답, 전송량 검증 실패는 별도 메시지로 구분
+// 영수증 대기 중 실패한 경우 이미 전송된 트랜잭션 정보를 result로 함께 반환
+func abortSendError(c *gin.Context, result *model.SendResult, err error) {
+	status, body := sendErrorBody(err, "전송에 실패했습니다!")
+	var policyErr *model.PolicyError
+	if errors.As(err, &policyErr) && policyErr.ResetAt != nil {
+		retry := int64(math.Ceil(time.Until(*policyErr.ResetAt).Seconds()))
+		if retry < 1 {
+			retry = 1
+		}
+		c.Header("Retry-After", strconv.FormatInt(retry, 10))
+	}
+	if result != nil {
+		body["result"] = result
+	}
+	c.AbortWithStatusJSON(status, body)
+}
+
+// 전송 실패 응답 상태 코드와 본문, 구분되지 않는 에러는 message 사용
+func sendErrorBody(err error, message string) (int, gin.H) {
+	status := http.StatusBadRequest
+	var policyErr *model.PolicyError
+	if errors.As(err, &policyErr) {
+		// 한 번에 보낼 수 있는 양을 넘으면 다시 보내도 거부되므로 403, 일일 한도는 resetAt 이후 다시 보낼 수 있어 429
+		status, message = http.StatusTooManyRequests, "전송 한도를 초과했습니다!"
+		if policyErr.ResetAt == nil {
+			status = http.StatusForbidden
+		}
+		return status, gin.H{
+			"message": message,
 			"error":   err.Error(),
-		})
+			"limit":   policyErr,
+		}
+	} else if errors.Is(err, model.ErrBlockedAddress) {
+		status, message = http.StatusForbidden, "전송할 수 없는 주소입니다!"
+	} else if errors.Is(err, model.ErrInvalidAmount) {
+		message = "amount 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrInvalidAddress) {
+		message = "address 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrUnknownToken) || errors.Is(err, model.ErrAmbiguousToken) {
+		message = "token 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrExecutionReverted) {
+		message = "트랜잭션이 revert 될 것으로 예상되어 전송하지 않았습니다!"
+	} else if errors.Is(err, model.ErrTxReverted) {
+		message = "트랜잭션이 revert 되었습니다!"
+	} else if errors.Is(err, model.ErrWaitTimeout) {
+		status, message = http.StatusGatewayTimeout, "영수증 대기 시간이 초과되었습니다!"
+	} else if errors.Is(err, model.ErrNoContractCode) {
+		message = "배포 주소에 컨트랙트 코드가 없습니다!"
+	} else if errors.Is(err, model.ErrInvalidRawTx) {
+		message = "rawTx 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrChainIDMismatch) {
+		message = "트랜잭션의 체인 ID가 다릅니다!"
+	} else if errors.Is(err, model.ErrSenderMismatch) {
+		message = "트랜잭션 서명 계정이 from과 다릅니다!"
+	} else if errors.Is(err, model.ErrInvalidTxTarget) {
+		message = "허용되지 않은 트랜잭션 대상입니다!"
+	}
+
+	return status, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	}
+}
+
+func (p *Controller) SearchTokenSymbolByTokenNameController(c *gin.Context) {
+	tokenName := c.Query("tokenName")
+	symbol, err := p.md.SearchTokenSymbolByTokenNameModel(c.Query("token"), tokenName)
+
+	if err != nil {
+		abortSearchError(c, "symbol을 가져오지 못했습니다!", err)
 		return
 	}
 
-	c.JSON(200, gin.H{"msg": "ok"})
+	c.JSON(200, gin.H{"symbol": symbol})
 }
 
-func (p *Controller) SendTokenByAddressWithPrivateKeyController(c *gin.Context) {
+func (p *Controller) SearchTokenBalanceByAddressController(c *gin.Context) {
 
 	address := c.GetHeader("address")
 	if address == "" {
@@ -89,73 +203,74 @@ func (p *Controller) SendTokenByAddressWithPrivateKeyController(c *gin.Context)
 		})
 		return
 	}
-	privateKey := c.GetHeader("privateKey")
-	if address == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "privateKey 정보가 유효하지 않습니다",
-		})
+
+	at, ok := blockAtQuery(c)
+	if !ok {
 		return
 	}
-	err := p.md.SendTokenByAddressModel(address, privateKey)
+	balance, err := p.md.SearchTokenBalanceByAddressModel(c.Query("token"), address, at)
 
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "전송에 실패했습니다!",
-			"error":   err.Error(),
-		})
+		abortBalanceError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{"msg": "ok"})
+	c.JSON(200, balance)
 }
 
-func (p *Controller) SendWemixCoinByAddressController(c *gin.Context) {
+func (p *Controller) SendTokenByAddressController(c *gin.Context) {
+	req, ok := sendRequestFromContext(c)
+	if !ok {
+		return
+	}
+	req.Token = c.Query("token")
+	result, err := p.md.SendTokenByAddressModel(req)
 
-	address := c.GetHeader("address")
-	if address == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "address 정보가 유효하지 않습니다",
-		})
+	if err != nil {
+		abortSendError(c, result, err)
 		return
 	}
-	err := p.md.SendWemixCoinByAddressModel(address, "")
+
+	c.JSON(200, result)
+}
+
+func (p *Controller) SendWemixCoinByAddressController(c *gin.Context) {
+	req, ok := sendRequestFromContext(c)
+	if !ok {
+		return
+	}
+	result, err := p.md.SendWemixCoinByAddressModel(req)
 
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "전송에 실패했습니다!",
-			"error":   err.Error(),
-		})
+		abortSendError(c, result, err)
 		return
 	}
 
-	c.JSON(200, gin.H{"msg": "ok"})
+	c.JSON(200, result)
 }
 
-func (p *Controller) SendWemixCoinByAddressWithPrivateKeyController(c *gin.Context) {
+func (p *Controller) SearchTransactionByHashController(c *gin.Context) {
+	status, err := p.md.SearchTransactionByHashModel(c.Param("hash"))
 
-	address := c.GetHeader("address")
-	if address == "" {
+	if errors.Is(err, model.ErrInvalidTxHash) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "address 정보가 유효하지 않습니다",
+			"message": "hash 정보가 유효하지 않습니다",
+			"error":   err.Error(),
 		})
 		return
-	}
-	privateKey := c.GetHeader("privateKey")
-	if address == "" {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "privateKey 정보가 유효하지 않습니다",
+	} else if errors.Is(err, model.ErrTxNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"message": "트랜잭션을 찾을 수 없습니다!",
+			"error":   err.Error(),
 		})
 		return
-	}
-	err := p.md.SendWemixCoinByAddressModel(address, privateKey)
-
-	if err != nil {
+	} else if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"message": "전송에 실패했습니다!",
+			"message": "트랜잭션을 조회하지 못했습니다!",
 			"error":   err.Error(),
 		})
 		return
 	}
 
-	c.JSON(200, gin.H{"msg": "ok"})
+	c.JSON(200, status)
 }
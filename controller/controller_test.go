@@ -1,37 +1,515 @@
-package controller
+package controller_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
 	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	conf "go-contract/config"
+	cont "go-contract/contracts"
+	ctl "go-contract/controller"
+	log "go-contract/logger"
+	md "go-contract/model"
+	rt "go-contract/router"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
 )
 
-/*
-func TestTransferWemix(t *testing.T) {
-	transfer.TransferWemix()
+// SimulatedBackend에는 NetworkID가 없어 체인 설정의 ChainID로 대신함
+type simBackend struct {
+	*backends.SimulatedBackend
 }
 
-func TestTransferCtxCoz(t *testing.T) {
-	transfer.TransferCtxCoz()
+func (b *simBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	return b.Blockchain().Config().ChainID, nil
 }
-*/
 
-func TestSearchTokenSymbolByTokenNameController(t *testing.T) {
+type testEnv struct {
+	t       *testing.T
+	cfg     *conf.Config
+	mod     *md.Model
+	sim     *simBackend
+	engine  *gin.Engine
+	owner   *ecdsa.PrivateKey
+	other   *ecdsa.PrivateKey
+	token   common.Address
+	caller  *cont.ContractsCaller
+	chainID *big.Int
+}
+
+// 시뮬레이션 체인에 YKKToken을 배포하고 실제 라우터를 구성
+// opts로 테스트별 config 값을 변경
+func newTestEnv(t *testing.T, opts ...func(*conf.Config)) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	owner, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	funds, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	sim := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(owner.PublicKey): {Balance: funds},
+		crypto.PubkeyToAddress(other.PublicKey): {Balance: funds},
+	}, 30000000)}
+	t.Cleanup(func() { sim.Close() })
+
+	chainID := sim.Blockchain().Config().ChainID
+	auth, err := bind.NewKeyedTransactorWithChainID(owner, chainID)
+	if err != nil {
+		t.Fatalf("NewKeyedTransactorWithChainID Error: %s", err)
+	}
+	token, _, _, err := cont.DeployContracts(auth, sim)
+	if err != nil {
+		t.Fatalf("DeployContracts Error: %s", err)
+	}
+	sim.Commit()
+
+	cfg := new(conf.Config)
+	cfg.Contract.PrivateKey = hexutil.Encode(crypto.FromECDSA(owner))[2:]
+	cfg.Contract.TokenAddress = token.Hex()
+	cfg.Contract.WaitTimeout = 10
+	cfg.Log.Level = "debug"
+	cfg.Log.Fpath = filepath.Join(t.TempDir(), "test")
+	cfg.Log.Msize = 1
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := log.InitLogger(cfg); err != nil {
+		t.Fatalf("InitLogger Error: %s", err)
+	}
+
+	mod, err := md.NewModel(cfg, sim)
+	if err != nil {
+		t.Fatalf("NewModel Error: %s", err)
+	}
+	controller, err := ctl.NewCTL(mod)
+	if err != nil {
+		t.Fatalf("NewCTL Error: %s", err)
+	}
+	router, err := rt.NewRouter(controller)
+	if err != nil {
+		t.Fatalf("NewRouter Error: %s", err)
+	}
+	caller, err := cont.NewContractsCaller(token, sim)
+	if err != nil {
+		t.Fatalf("NewContractsCaller Error: %s", err)
+	}
 
-	req, err := http.NewRequest("GET", "http://localhost:8080/v1/token/symbol", nil)
+	return &testEnv{
+		t:       t,
+		cfg:     cfg,
+		mod:     mod,
+		sim:     sim,
+		engine:  router.Idx(),
+		owner:   owner,
+		other:   other,
+		token:   token,
+		caller:  caller,
+		chainID: chainID,
+	}
+}
+
+// 같은 YKKToken 컨트랙트를 other 계정으로 하나 더 배포
+// 서비스 지갑의 nonce를 건드리지 않도록 other 계정을 사용하며, 초기 발행량은 other가 가짐
+func (e *testEnv) deployToken() common.Address {
+	e.t.Helper()
+	auth, err := bind.NewKeyedTransactorWithChainID(e.other, e.chainID)
+	if err != nil {
+		e.t.Fatalf("NewKeyedTransactorWithChainID Error: %s", err)
+	}
+	token, _, _, err := cont.DeployContracts(auth, e.sim)
 	if err != nil {
-		t.Errorf("request Error: %s", err)
+		e.t.Fatalf("DeployContracts Error: %s", err)
 	}
+	e.sim.Commit()
+	return token
+}
 
-	q := req.URL.Query()
-	q.Add("tokenName", "YKK Token")
-	req.URL.RawQuery = q.Encode()
+func (e *testEnv) request(method string, path string, headers map[string]string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	e.engine.ServeHTTP(w, req)
+	return w
+}
 
-	client := http.Client{}
-	res, err := client.Do(req)
+func (e *testEnv) requestJSON(method string, path string, body interface{}) *httptest.ResponseRecorder {
+	e.t.Helper()
+	data, err := json.Marshal(body)
 	if err != nil {
-		t.Errorf("TestSearchTokenSymbolByTokenNameController Error: %s", err)
+		e.t.Fatalf("request encode Error: %s", err)
 	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	e.engine.ServeHTTP(w, req)
+	return w
+}
 
-	t.Log(res.Body)
+func (e *testEnv) decode(w *httptest.ResponseRecorder, out interface{}) {
+	e.t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+		e.t.Fatalf("response decode Error: %s, body: %s", err, w.Body.String())
+	}
+}
+
+func (e *testEnv) tokenBalance(addr common.Address) *big.Int {
+	e.t.Helper()
+	balance, err := e.caller.BalanceOf(&bind.CallOpts{}, addr)
+	if err != nil {
+		e.t.Fatalf("BalanceOf Error: %s", err)
+	}
+	return balance
+}
+
+func (e *testEnv) coinBalance(addr common.Address) *big.Int {
+	e.t.Helper()
+	balance, err := e.sim.BalanceAt(context.Background(), addr, nil)
+	if err != nil {
+		e.t.Fatalf("BalanceAt Error: %s", err)
+	}
+	return balance
+}
+
+// 주기적으로 블록을 생성, 반환된 함수로 중지하며 중지 후에는 Commit이 호출되지 않음
+func (e *testEnv) autoCommit(interval time.Duration) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				e.sim.Commit()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// build 경로로 만든 트랜잭션에 key로 서명한 rawTx
+func (e *testEnv) signRawTx(key *ecdsa.PrivateKey, rawTx string) string {
+	e.t.Helper()
+	data, err := hexutil.Decode(rawTx)
+	if err != nil {
+		e.t.Fatalf("rawTx decode Error: %s", err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		e.t.Fatalf("UnmarshalBinary Error: %s", err)
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(e.chainID), key)
+	if err != nil {
+		e.t.Fatalf("SignTx Error: %s", err)
+	}
+	raw, _ := signed.MarshalBinary()
+	return hexutil.Encode(raw)
+}
+
+// other 계정으로 build 경로에서 트랜잭션을 만들고 서명해 broadcast로 전송
+// build가 실패하면 build 응답을 반환
+func (e *testEnv) sendSigned(path string, headers map[string]string) *httptest.ResponseRecorder {
+	e.t.Helper()
+	headers["from"] = crypto.PubkeyToAddress(e.other.PublicKey).Hex()
+	w := e.request("POST", path, headers)
+	if w.Code != http.StatusOK {
+		return w
+	}
+	var unsigned md.UnsignedTx
+	e.decode(w, &unsigned)
+	return e.requestJSON("POST", "/v1/tx/broadcast", ctl.BroadcastTxRequest{RawTx: e.signRawTx(e.other, unsigned.RawTx), From: unsigned.From})
+}
+
+func newAddress() common.Address {
+	key, _ := crypto.GenerateKey()
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func ether(v string) *big.Int {
+	n, _ := new(big.Int).SetString(v, 10)
+	return n
+}
+
+func TestSearchTokenSymbolByTokenNameController(t *testing.T) {
+	e := newTestEnv(t)
+
+	w := e.request("GET", "/v1/token/symbol?tokenName=YKK+Token", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res struct{ Symbol string }
+	e.decode(w, &res)
+	if res.Symbol != "YKK" {
+		t.Errorf("symbol = %s, want YKK", res.Symbol)
+	}
+
+	if w := e.request("GET", "/v1/token/symbol?tokenName=Other", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown token name status = %d, want 400", w.Code)
+	}
+}
+
+func TestSearchTokenBalanceByAddressController(t *testing.T) {
+	e := newTestEnv(t)
+
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	w := e.request("GET", "/v1/token/balance", map[string]string{"address": owner.Hex()})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res struct{ Balance *big.Int }
+	e.decode(w, &res)
+	if want := ether("1000000000000000000000000000"); res.Balance.Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", res.Balance, want)
+	}
 
+	if w := e.request("GET", "/v1/token/balance", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("missing address status = %d, want 400", w.Code)
+	}
+}
+
+func TestSendTokenByAddressController(t *testing.T) {
+	e := newTestEnv(t)
+
+	to := newAddress()
+	w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "12.5"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	e.sim.Commit()
+
+	if want := ether("12500000000000000000"); e.tokenBalance(to).Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", e.tokenBalance(to), want)
+	}
+	if res.To != to.Hex() || res.Token != e.token.Hex() || res.ChainID != e.chainID.String() || res.GasLimit >= 200000 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	// 영수증 조회
+	w = e.request("GET", "/v1/tx/"+res.TxHash, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("tx status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var status md.TxStatus
+	e.decode(w, &status)
+	if status.Status != md.TxStatusSucceeded || len(status.Transfers) != 1 || status.Transfers[0].To != to.Hex() {
+		t.Errorf("unexpected tx status: %+v", status)
+	}
+}
+
+func TestSendSignedTokenTxController(t *testing.T) {
+	e := newTestEnv(t)
+
+	// other 계정에 토큰을 먼저 보내 둠
+	other := crypto.PubkeyToAddress(e.other.PublicKey)
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": other.Hex(), "amount": "10"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	to := newAddress()
+	w := e.sendSigned("/v1/token/build", map[string]string{
+		"address": to.Hex(),
+		"amount":  "3",
+		"unit":    "base",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	e.sim.Commit()
+
+	if res.From != other.Hex() {
+		t.Errorf("from = %s, want %s", res.From, other.Hex())
+	}
+	if e.tokenBalance(to).Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("balance = %s, want 3", e.tokenBalance(to))
+	}
+}
+
+func TestSendTokenConcurrentController(t *testing.T) {
+	e := newTestEnv(t)
+
+	// 같은 서비스 지갑으로 동시에 전송해도 nonce가 겹치지 않아야 함
+	targets := make([]common.Address, 5)
+	var wg sync.WaitGroup
+	for i := range targets {
+		targets[i] = newAddress()
+		wg.Add(1)
+		go func(to common.Address) {
+			defer wg.Done()
+			if w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+				t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+		}(targets[i])
+	}
+	wg.Wait()
+	e.sim.Commit()
+
+	for _, to := range targets {
+		if want := ether("1000000000000000000"); e.tokenBalance(to).Cmp(want) != 0 {
+			t.Errorf("balance of %s = %s, want %s", to.Hex(), e.tokenBalance(to), want)
+		}
+	}
+}
+
+func TestSendTokenRevertController(t *testing.T) {
+	e := newTestEnv(t)
+
+	// 토큰이 없는 계정으로 전송하면 가스 예측 단계에서 revert가 확인되어야 함
+	w := e.sendSigned("/v1/token/build", map[string]string{
+		"address": newAddress().Hex(),
+		"amount":  "1",
+	})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "panic 0x11") {
+		t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSendWemixCoinByAddressController(t *testing.T) {
+	e := newTestEnv(t)
+
+	to := newAddress()
+	w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "0.7"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	if want := ether("700000000000000000"); e.coinBalance(to).Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", e.coinBalance(to), want)
+	}
+}
+
+func TestSendSignedCoinTxController(t *testing.T) {
+	e := newTestEnv(t)
+
+	to := newAddress()
+	w := e.sendSigned("/v1/coin/build", map[string]string{
+		"address": to.Hex(),
+		"amount":  "2",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	e.sim.Commit()
+
+	if res.From != crypto.PubkeyToAddress(e.other.PublicKey).Hex() {
+		t.Errorf("from = %s, want other account", res.From)
+	}
+	if want := ether("2000000000000000000"); e.coinBalance(to).Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", e.coinBalance(to), want)
+	}
+}
+
+func TestSendDynamicFeeController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Contract.DynamicFee = true
+		cfg.Contract.MaxPriorityFeePerGas = 1
+	})
+
+	to := newAddress()
+	w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "1"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	e.sim.Commit()
+
+	if res.Type != types.DynamicFeeTxType || res.MaxFeePerGas == "" || res.MaxPriorityFeePerGas != "1" || res.GasPrice != "" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if want := ether("1000000000000000000"); e.coinBalance(to).Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", e.coinBalance(to), want)
+	}
+}
+
+func TestSendDynamicFeeCapTooLowController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Contract.DynamicFee = true
+		cfg.Contract.MaxFeePerGas = 1
+	})
+
+	w := e.request("POST", "/v1/coin/", map[string]string{"address": newAddress().Hex(), "amount": "1"})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), md.ErrFeeCapTooLow.Error()) {
+		t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSendWithWaitController(t *testing.T) {
+	e := newTestEnv(t)
+
+	// 대기하는 동안 블록을 계속 생성
+	defer e.autoCommit(100 * time.Millisecond)()
+
+	to := newAddress()
+	w := e.request("POST", "/v1/coin/?wait=true&confirmations=2", map[string]string{"address": to.Hex(), "amount": "1"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	if res.Receipt == nil || res.Receipt.Status != md.TxStatusSucceeded || res.Receipt.Confirmations < 2 {
+		t.Errorf("unexpected receipt: %+v", res.Receipt)
+	}
+}
+
+func TestSendInvalidAmountController(t *testing.T) {
+	e := newTestEnv(t)
+
+	to := newAddress().Hex()
+	tests := []map[string]string{
+		{"address": to},
+		{"address": to, "amount": "-1"},
+		{"address": to, "amount": "0.0000000000000000001"},
+		{"address": to, "amount": "1.5", "unit": "base"},
+		{"address": to, "amount": "1", "unit": "wei"},
+	}
+	for _, headers := range tests {
+		w := e.request("POST", "/v1/token/", headers)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "정보가 유효하지 않습니다") {
+			t.Errorf("headers %v: status = %d, body: %s", headers, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestSearchTransactionByHashController(t *testing.T) {
+	e := newTestEnv(t)
+
+	if w := e.request("GET", "/v1/tx/0x1234", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid hash status = %d, want 400", w.Code)
+	}
+	if w := e.request("GET", "/v1/tx/"+common.Hash{1}.Hex(), nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown hash status = %d, want 404", w.Code)
+	}
 }
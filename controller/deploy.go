@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// 서비스 지갑으로 YKKToken을 배포, 영수증과 bytecode 확인까지 마친 뒤 응답
+// confirmations, timeout 쿼리로 영수증 대기 조건을 지정할 수 있음
+func (p *Controller) DeployContractController(c *gin.Context) {
+	wait, ok := waitOptionFromQuery(c)
+	if !ok {
+		return
+	}
+	result, err := p.md.DeployTokenModel(wait)
+
+	if err != nil {
+		status, body := sendErrorBody(err, "배포에 실패했습니다!")
+		if result != nil {
+			body["result"] = result
+		}
+		c.AbortWithStatusJSON(status, body)
+		return
+	}
+
+	c.JSON(200, result)
+}
@@ -0,0 +1,63 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDeployContractController(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Contract.StatePath = statePath
+	})
+
+	stop := e.autoCommit(50 * time.Millisecond)
+	w := e.request("POST", "/v1/contracts/deploy", nil)
+	stop()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.DeployResult
+	e.decode(w, &res)
+
+	code, err := e.sim.CodeAt(context.Background(), common.HexToAddress(res.Address), nil)
+	if err != nil || len(code) == 0 {
+		t.Fatalf("CodeAt = %d bytes, %v", len(code), err)
+	}
+	if res.Token == nil || res.Token.Symbol != "YKK" || res.Token.Default || res.Receipt == nil || res.Receipt.Status != md.TxStatusSucceeded {
+		t.Errorf("unexpected result: %s", w.Body.String())
+	}
+
+	// 배포한 토큰은 바로 레지스트리에서 사용할 수 있어야 함
+	if w := e.request("GET", "/v1/token/totalSupply?token="+res.Address, nil); w.Code != http.StatusOK {
+		t.Errorf("totalSupply status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// 재시작하면 state 파일의 배포 기록으로 레지스트리에 등록되고, tokenAddress가 없으면 기본 토큰이 됨
+	cfg := *e.cfg
+	cfg.Contract.TokenAddress = ""
+	mod, err := md.NewModel(&cfg, e.sim)
+	if err != nil {
+		t.Fatalf("NewModel Error: %s", err)
+	}
+	info, err := mod.SearchTokenInfoModel("")
+	if err != nil || info.Address != res.Address || info.Decimals != 18 {
+		t.Errorf("default token = %+v, %v", info, err)
+	}
+}
+
+func TestDeployContractInvalidQueryController(t *testing.T) {
+	e := newTestEnv(t)
+
+	if w := e.request("POST", "/v1/contracts/deploy?confirmations=0", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 블록 번호 쿼리, 비어있으면 nil
+func blockQuery(c *gin.Context, name string) (*uint64, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	block, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": name + " 정보가 유효하지 않습니다",
+		})
+		return nil, false
+	}
+	return &block, true
+}
+
+// address의 토큰 전송 내역 조회
+// from_block, to_block으로 블록 구간을, direction(in, out)으로 받은 전송과 보낸 전송을 지정
+// 응답의 next를 cursor 쿼리로 넘기면 다음 페이지 조회
+func (p *Controller) SearchTransfersController(c *gin.Context) {
+	q := &model.TransferQuery{
+		Token:     c.Query("token"),
+		Address:   c.Query("address"),
+		Direction: c.Query("direction"),
+		Cursor:    c.Query("cursor"),
+	}
+	var ok bool
+	if q.FromBlock, ok = blockQuery(c, "from_block"); !ok {
+		return
+	}
+	if q.ToBlock, ok = blockQuery(c, "to_block"); !ok {
+		return
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": "limit 정보가 유효하지 않습니다",
+			})
+			return
+		}
+		q.Limit = limit
+	}
+
+	page, err := p.md.SearchTransfersModel(q)
+
+	if errors.Is(err, model.ErrInvalidDirection) {
+		abortSearchError(c, "direction 정보가 유효하지 않습니다", err)
+		return
+	} else if errors.Is(err, model.ErrInvalidBlockRange) {
+		abortSearchError(c, "블록 구간이 유효하지 않습니다", err)
+		return
+	} else if errors.Is(err, model.ErrInvalidCursor) {
+		abortSearchError(c, "cursor 정보가 유효하지 않습니다", err)
+		return
+	} else if err != nil {
+		abortSearchError(c, "전송 내역을 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, page)
+}
@@ -0,0 +1,83 @@
+package controller_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	conf "go-contract/config"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSearchTransfersController(t *testing.T) {
+	// 구간을 작게 잡아 여러 구간에 걸쳐 조회되도록 함
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Contract.LogRange = 2
+	})
+	other := crypto.PubkeyToAddress(e.other.PublicKey)
+
+	for i := 1; i <= 3; i++ {
+		if w := e.request("POST", "/v1/token/", map[string]string{"address": other.Hex(), "amount": strconv.Itoa(i)}); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		e.sim.Commit()
+	}
+	if w := e.sendSigned("/v1/token/build", map[string]string{
+		"address": newAddress().Hex(),
+		"amount":  "1",
+	}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	search := func(query string) md.TransferPage {
+		t.Helper()
+		w := e.request("GET", "/v1/token/transfers?address="+other.Hex()+query, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		var page md.TransferPage
+		e.decode(w, &page)
+		return page
+	}
+
+	if page := search("&direction=in"); len(page.Transfers) != 3 || page.Transfers[0].Value != ether("1000000000000000000").String() {
+		t.Errorf("in transfers = %+v", page.Transfers)
+	}
+	if page := search("&direction=out"); len(page.Transfers) != 1 || page.Transfers[0].From != other.Hex() {
+		t.Errorf("out transfers = %+v", page.Transfers)
+	}
+	all := search("")
+	if len(all.Transfers) != 4 || all.Next != "" {
+		t.Fatalf("all transfers = %+v", all)
+	}
+	for i := 1; i < len(all.Transfers); i++ {
+		if all.Transfers[i].BlockNumber <= all.Transfers[i-1].BlockNumber {
+			t.Errorf("transfers not sorted: %+v", all.Transfers)
+		}
+	}
+
+	// limit과 cursor로 나눠 조회한 결과가 한 번에 조회한 결과와 같아야 함
+	first := search("&limit=3")
+	if len(first.Transfers) != 3 || first.Next == "" {
+		t.Fatalf("first page = %+v", first)
+	}
+	second := search("&limit=3&cursor=" + first.Next)
+	if len(second.Transfers) != 1 || second.Next != "" || second.Transfers[0] != all.Transfers[3] {
+		t.Errorf("second page = %+v", second)
+	}
+
+	// 블록 구간 지정
+	ranged := search("&from_block=" + strconv.FormatUint(all.Transfers[1].BlockNumber, 10) + "&to_block=" + strconv.FormatUint(all.Transfers[2].BlockNumber, 10))
+	if len(ranged.Transfers) != 2 || ranged.Transfers[0] != all.Transfers[1] {
+		t.Errorf("ranged transfers = %+v", ranged.Transfers)
+	}
+
+	for _, query := range []string{"&direction=both", "&from_block=10&to_block=5", "&cursor=abc", "&limit=0"} {
+		if w := e.request("GET", "/v1/token/transfers?address="+other.Hex()+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, body: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
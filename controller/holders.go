@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 0 이상 정수 쿼리, 비어있으면 0
+func intQuery(c *gin.Context, name string) (int, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": name + " 정보가 유효하지 않습니다",
+		})
+		return 0, false
+	}
+	return n, true
+}
+
+// 색인한 Transfer 이벤트로 찾은 토큰 보유자를 BalanceOf로 확인한 보유량 순으로 조회
+// sort(desc, asc), offset, limit으로 페이지를, top으로 보유 비율을 합산할 상위 보유자 수를 지정
+func (p *Controller) SearchTokenHoldersController(c *gin.Context) {
+	q := &model.HolderQuery{
+		Token: c.Query("token"),
+		Sort:  c.Query("sort"),
+	}
+	var ok bool
+	if q.Offset, ok = intQuery(c, "offset"); !ok {
+		return
+	}
+	if q.Limit, ok = intQuery(c, "limit"); !ok {
+		return
+	}
+	if q.Top, ok = intQuery(c, "top"); !ok {
+		return
+	}
+
+	page, err := p.md.SearchTokenHoldersModel(q)
+
+	if errors.Is(err, model.ErrInvalidSort) {
+		abortSearchError(c, "sort 정보가 유효하지 않습니다", err)
+		return
+	} else if errors.Is(err, model.ErrTokenNotIndexed) {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"message": "토큰을 색인하지 않았습니다",
+			"error":   err.Error(),
+		})
+		return
+	} else if err != nil {
+		abortSearchError(c, "보유자 목록을 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, page)
+}
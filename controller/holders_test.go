@@ -0,0 +1,193 @@
+package controller_test
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	conf "go-contract/config"
+	cont "go-contract/contracts"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSearchTokenHoldersController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+		cfg.Indexer.PollInterval = 1
+	})
+	defer e.mod.Close()
+
+	// 색인 전에는 조회할 수 없음
+	if w := e.request("GET", "/v1/token/holders", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("not indexed status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	a, b := newAddress(), newAddress()
+	for _, send := range []struct{ to, amount string }{{a.Hex(), "3"}, {b.Hex(), "1"}} {
+		if w := e.request("POST", "/v1/token/", map[string]string{"address": send.to, "amount": send.amount}); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		e.sim.Commit()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.mod.RunIndexer(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, "index head", func() bool {
+		var status md.IndexerStatus
+		e.decode(e.request("GET", "/v1/indexer", nil), &status)
+		return status.Head != nil && *status.Head == 3
+	})
+
+	var page md.HolderPage
+	e.decode(e.request("GET", "/v1/token/holders?top=2", nil), &page)
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	supply := ether("1000000000000000000000000000")
+	if page.HolderCount != 3 || len(page.Holders) != 3 || page.TotalSupply != supply.String() || page.Block != 3 {
+		t.Fatalf("holders = %+v", page)
+	}
+	if page.Holders[0].Address != owner.Hex() || page.Holders[1].Address != a.Hex() || page.Holders[2].Address != b.Hex() {
+		t.Errorf("order = %+v", page.Holders)
+	}
+	// 생성자 발행량은 Transfer 이벤트가 없어 색인으로 계산한 값과 다름
+	if page.Holders[0].IndexedBalance != "-4000000000000000000" || page.Holders[1].IndexedBalance != "" {
+		t.Errorf("indexed balances = %+v", page.Holders)
+	}
+	if page.Holders[1].Balance != ether("3000000000000000000").String() || page.Holders[2].Share != "0.0000" {
+		t.Errorf("holder = %+v", page.Holders)
+	}
+	if top := new(big.Int).Sub(supply, ether("1000000000000000000")); page.TopBalance != top.String() {
+		t.Errorf("topBalance = %s, want %s", page.TopBalance, top)
+	}
+
+	page = md.HolderPage{}
+	e.decode(e.request("GET", "/v1/token/holders?sort=asc&limit=1&offset=1", nil), &page)
+	if len(page.Holders) != 1 || page.Holders[0].Address != a.Hex() || page.HolderCount != 3 {
+		t.Errorf("asc page = %+v", page)
+	}
+
+	for _, query := range []string{"sort=size", "limit=-1", "top=x"} {
+		if w := e.request("GET", "/v1/token/holders?"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+// startBlock을 토큰 배포 이후 블록으로 설정해도 색인한 범위로 조회
+func TestSearchTokenHoldersStartBlockController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+		cfg.Indexer.PollInterval = 1
+		cfg.Indexer.StartBlock = 2
+	})
+	defer e.mod.Close()
+
+	a := newAddress()
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": a.Hex(), "amount": "2"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.mod.RunIndexer(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, "index head", func() bool {
+		var status md.IndexerStatus
+		e.decode(e.request("GET", "/v1/indexer", nil), &status)
+		return status.Head != nil && *status.Head == 2
+	})
+
+	w := e.request("GET", "/v1/token/holders", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var page md.HolderPage
+	e.decode(w, &page)
+	if page.HolderCount != 2 || page.Block != 2 || page.Holders[1].Address != a.Hex() || page.Holders[1].Balance != ether("2000000000000000000").String() {
+		t.Errorf("holders = %+v", page)
+	}
+}
+
+// 색인 중에 등록한 토큰은 등록 전의 전송도 색인한 뒤에 조회
+func TestSearchTokenHoldersRegisteredTokenController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+		cfg.Indexer.PollInterval = 1
+	})
+	defer e.mod.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.mod.RunIndexer(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, "index head", func() bool {
+		var status md.IndexerStatus
+		e.decode(e.request("GET", "/v1/indexer", nil), &status)
+		return status.Head != nil && *status.Head == 1
+	})
+
+	// 등록 전에 배포하고 전송한 토큰
+	second := e.deployToken()
+	a := newAddress()
+	auth, _ := bind.NewKeyedTransactorWithChainID(e.other, e.chainID)
+	instance, _ := cont.NewContracts(second, e.sim)
+	if _, err := instance.Transfer(auth, a, big.NewInt(5)); err != nil {
+		t.Fatalf("Transfer Error: %s", err)
+	}
+	e.sim.Commit()
+	waitFor(t, "index head", func() bool {
+		var status md.IndexerStatus
+		e.decode(e.request("GET", "/v1/indexer", nil), &status)
+		return status.Head != nil && *status.Head == 3
+	})
+
+	if w := e.requestJSON("POST", "/v1/tokens/", ctl.AddTokenRequest{Address: second.Hex()}); w.Code != http.StatusOK {
+		t.Fatalf("add status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("GET", "/v1/token/holders?token="+second.Hex(), nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("not indexed status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	var page md.HolderPage
+	waitFor(t, "registered token holders", func() bool {
+		w := e.request("GET", "/v1/token/holders?token="+second.Hex(), nil)
+		if w.Code != http.StatusOK {
+			return false
+		}
+		e.decode(w, &page)
+		return true
+	})
+	if page.Block != 4 || len(page.Holders) != 1 || page.Holders[0].Address != a.Hex() || page.Holders[0].Balance != "5" {
+		t.Errorf("holders = %+v", page)
+	}
+}
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 로컬 색인 상태, 색인한 블록과 토큰별 색인 시작 블록
+func (p *Controller) IndexerStatusController(c *gin.Context) {
+	status, err := p.md.IndexerStatusModel()
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "색인 상태를 가져오지 못했습니다!",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, status)
+}
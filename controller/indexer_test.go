@@ -0,0 +1,111 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	conf "go-contract/config"
+	md "go-contract/model"
+)
+
+func TestIndexerStatusController(t *testing.T) {
+	e := newTestEnv(t)
+	var status md.IndexerStatus
+	e.decode(e.request("GET", "/v1/indexer", nil), &status)
+	if status.Enabled || status.Head != nil || len(status.Tokens) != 0 {
+		t.Errorf("disabled status = %+v", status)
+	}
+
+	e = newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+		cfg.Indexer.PollInterval = 1
+	})
+	defer e.mod.Close()
+	to := newAddress()
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.mod.RunIndexer(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, "index head", func() bool {
+		status = md.IndexerStatus{}
+		e.decode(e.request("GET", "/v1/indexer", nil), &status)
+		return status.Head != nil && *status.Head == 2
+	})
+	if !status.Enabled || len(status.Tokens) != 1 || status.Tokens[0].Address != e.token.Hex() {
+		t.Errorf("enabled status = %+v", status)
+	}
+
+	// 색인한 구간은 로컬 색인에서 조회
+	var page md.TransferPage
+	e.decode(e.request("GET", "/v1/token/transfers?address="+to.Hex(), nil), &page)
+	if len(page.Transfers) != 1 || page.ToBlock != 2 || page.Transfers[0].To != to.Hex() {
+		t.Errorf("transfers = %+v", page)
+	}
+}
+
+// from_block이 없으면 토큰을 색인하기 시작한 블록부터 로컬 색인에서 조회
+func TestIndexerStartBlockController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Indexer.Enabled = true
+		cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+		cfg.Indexer.PollInterval = 1
+		cfg.Indexer.StartBlock = 2
+	})
+	defer e.mod.Close()
+	to := newAddress()
+	for i := 0; i < 2; i++ {
+		if w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		e.sim.Commit()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.mod.RunIndexer(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	waitFor(t, "index head", func() bool {
+		var status md.IndexerStatus
+		e.decode(e.request("GET", "/v1/indexer", nil), &status)
+		return status.Head != nil && *status.Head == 3
+	})
+	cancel()
+	<-done
+	// 색인을 멈춘 뒤 만든 블록은 로컬 색인에서 조회하면 포함되지 않음
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	var page md.TransferPage
+	e.decode(e.request("GET", "/v1/token/transfers?address="+to.Hex(), nil), &page)
+	if len(page.Transfers) != 2 || page.FromBlock != 2 || page.ToBlock != 3 {
+		t.Errorf("transfers = %+v", page)
+	}
+	// 색인 시작 블록 이전부터 조회하면 rpc 노드에서 조회
+	page = md.TransferPage{}
+	e.decode(e.request("GET", "/v1/token/transfers?address="+to.Hex()+"&from_block=0", nil), &page)
+	if len(page.Transfers) != 3 || page.FromBlock != 0 || page.ToBlock != 4 {
+		t.Errorf("rpc transfers = %+v", page)
+	}
+}
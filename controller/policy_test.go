@@ -0,0 +1,84 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+
+	conf "go-contract/config"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type policyRule = struct {
+	Token               string
+	MaxPerTransfer      string
+	DailyPerKey         string
+	DailyPerDestination string
+	DailyTotal          string
+}
+
+type policyErrorBody struct {
+	Message string         `json:"message"`
+	Limit   md.PolicyError `json:"limit"`
+}
+
+func TestTransferPolicyController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Policy.Rules = []policyRule{
+			{Token: "coin", MaxPerTransfer: "1", DailyPerDestination: "1.5"},
+			{Token: "*", DailyTotal: "5"},
+		}
+	})
+
+	// 한 번에 보낼 수 있는 양을 넘으면 403
+	to := newAddress()
+	w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "2"})
+	var body policyErrorBody
+	e.decode(w, &body)
+	if w.Code != http.StatusForbidden || body.Limit.Limit != md.LimitMaxPerTransfer || body.Limit.Max != "1000000000000000000" || body.Limit.ResetAt != nil {
+		t.Errorf("max per transfer status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// 같은 주소로 최근 24시간 한도를 넘으면 429와 한도가 풀리는 시각
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Fatalf("coin status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	w = e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "1"})
+	body = policyErrorBody{}
+	e.decode(w, &body)
+	if w.Code != http.StatusTooManyRequests || body.Limit.Limit != md.LimitDailyPerDestination || body.Limit.Used != "1000000000000000000" || body.Limit.ResetAt == nil || w.Header().Get("Retry-After") == "" {
+		t.Errorf("per destination status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": newAddress().Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Errorf("other destination status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	// rule이 없는 토큰은 * rule의 전체 한도 적용
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": newAddress().Hex(), "amount": "3"}); w.Code != http.StatusOK {
+		t.Fatalf("token status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	w = e.request("POST", "/v1/token/", map[string]string{"address": newAddress().Hex(), "amount": "3"})
+	body = policyErrorBody{}
+	e.decode(w, &body)
+	if w.Code != http.StatusTooManyRequests || body.Limit.Limit != md.LimitDailyTotal || body.Limit.Asset != e.token.Hex() {
+		t.Errorf("token total status = %d, body: %s", w.Code, w.Body.String())
+	}
+	// approve와 서비스 지갑의 토큰을 옮기는 transferFrom에도 같은 한도 적용
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: newAddress().Hex(), Amount: "10"}); w.Code != http.StatusForbidden {
+		t.Errorf("approve status = %d, body: %s", w.Code, w.Body.String())
+	}
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: owner.Hex(), Amount: "1"}); w.Code != http.StatusOK {
+		t.Fatalf("approve self status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	// 승인량 1을 더해 4를 사용, 서비스 지갑에서 2를 옮기면 전체 한도 5 초과
+	if w := e.requestJSON("POST", "/v1/token/transferFrom", ctl.TransferFromRequest{From: owner.Hex(), To: newAddress().Hex(), Amount: "2"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("transferFrom status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
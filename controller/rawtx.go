@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"go-contract/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 서명된 트랜잭션 전송 요청, 서명한 계정이 from과 같은지 확인
+type BroadcastTxRequest struct {
+	RawTx string `json:"rawTx" binding:"required"`
+	From  string `json:"from" binding:"required"`
+}
+
+// 서명 전 트랜잭션 생성 요청 정보, 전송 요청 헤더에 서명할 계정 from 헤더를 더함
+// 트랜잭션을 전송하지 않으므로 영수증 대기 쿼리(wait, confirmations, timeout)는 받지 않음
+func buildRequestFromContext(c *gin.Context) (*model.SendRequest, string, bool) {
+	for _, key := range []string{"wait", "confirmations", "timeout"} {
+		if _, ok := c.GetQuery(key); ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"message": key + " 정보가 유효하지 않습니다",
+				"error":   "트랜잭션 생성 요청은 영수증을 기다리지 않습니다. broadcast 요청에 지정해야 합니다",
+			})
+			return nil, "", false
+		}
+	}
+	req, ok := transferRequestFromContext(c)
+	if !ok {
+		return nil, "", false
+	}
+	from := c.GetHeader("from")
+	if from == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "from 정보가 유효하지 않습니다",
+		})
+		return nil, "", false
+	}
+	return req, from, true
+}
+
+// from 계정이 서명할 토큰 transfer 트랜잭션 생성, 서명한 트랜잭션은 /v1/tx/broadcast로 전송
+func (p *Controller) BuildTokenTxController(c *gin.Context) {
+	req, from, ok := buildRequestFromContext(c)
+	if !ok {
+		return
+	}
+	req.Token = c.Query("token")
+	unsigned, err := p.md.BuildTokenTxModel(req, from)
+
+	if err != nil {
+		abortSendError(c, nil, err)
+		return
+	}
+
+	c.JSON(200, unsigned)
+}
+
+// from 계정이 서명할 WEMIX 코인 전송 트랜잭션 생성
+func (p *Controller) BuildCoinTxController(c *gin.Context) {
+	req, from, ok := buildRequestFromContext(c)
+	if !ok {
+		return
+	}
+	unsigned, err := p.md.BuildCoinTxModel(req, from)
+
+	if err != nil {
+		abortSendError(c, nil, err)
+		return
+	}
+
+	c.JSON(200, unsigned)
+}
+
+// 클라이언트가 서명한 트랜잭션을 확인한 뒤 전송
+// wait=true면 confirmations, timeout(초) 쿼리로 영수증 대기 조건을 지정할 수 있음
+func (p *Controller) BroadcastTxController(c *gin.Context) {
+	var body BroadcastTxRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	var wait *model.WaitOption
+	if ok, _ := strconv.ParseBool(c.DefaultQuery("wait", "false")); ok {
+		if wait, ok = waitOptionFromQuery(c); !ok {
+			return
+		}
+	}
+	result, err := p.md.BroadcastTxModel(body.RawTx, body.From, wait)
+
+	if err != nil {
+		abortSendError(c, result, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
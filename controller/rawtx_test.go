@@ -0,0 +1,115 @@
+package controller_test
+
+import (
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestBuildAndBroadcastController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Contract.DynamicFee = true
+	})
+	other := crypto.PubkeyToAddress(e.other.PublicKey)
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": other.Hex(), "amount": "10"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	to := newAddress()
+	w := e.request("POST", "/v1/token/build", map[string]string{"address": to.Hex(), "amount": "4", "from": other.Hex()})
+	if w.Code != http.StatusOK {
+		t.Fatalf("build status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var unsigned md.UnsignedTx
+	e.decode(w, &unsigned)
+	if unsigned.Type != types.DynamicFeeTxType || unsigned.To != e.token.Hex() || unsigned.Recipient != to.Hex() || unsigned.Nonce != 0 || unsigned.MaxFeePerGas == "" {
+		t.Errorf("unsigned = %+v", unsigned)
+	}
+	raw := e.signRawTx(e.other, unsigned.RawTx)
+
+	// 서명 계정이 from과 다르면 전송하지 않음
+	if w := e.requestJSON("POST", "/v1/tx/broadcast", ctl.BroadcastTxRequest{RawTx: raw, From: newAddress().Hex()}); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "sender mismatch") {
+		t.Errorf("sender mismatch status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// 네트워크 형식(RLP로 감싼 typed 트랜잭션)도 받음
+	data, _ := hexutil.Decode(raw)
+	wrapped, _ := rlp.EncodeToBytes(data)
+	stop := e.autoCommit(50 * time.Millisecond)
+	w = e.requestJSON("POST", "/v1/tx/broadcast?wait=true&timeout=5", ctl.BroadcastTxRequest{RawTx: hexutil.Encode(wrapped), From: other.Hex()})
+	stop()
+	if w.Code != http.StatusOK {
+		t.Fatalf("broadcast status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	if res.Method != "transfer" || res.To != to.Hex() || res.From != other.Hex() || res.Amount != ether("4000000000000000000").String() {
+		t.Errorf("result = %+v", res)
+	}
+	if e.tokenBalance(to).Cmp(ether("4000000000000000000")) != 0 {
+		t.Errorf("balance = %s, want 4", e.tokenBalance(to))
+	}
+}
+
+func TestBroadcastValidationController(t *testing.T) {
+	e := newTestEnv(t)
+	other := crypto.PubkeyToAddress(e.other.PublicKey)
+
+	sign := func(tx *types.Transaction, chainID *big.Int) string {
+		signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), e.other)
+		if err != nil {
+			t.Fatalf("SignTx Error: %s", err)
+		}
+		raw, _ := signed.MarshalBinary()
+		return hexutil.Encode(raw)
+	}
+	to := newAddress()
+	gasPrice := big.NewInt(1000000000)
+	unsigned, _ := types.NewTransaction(0, to, big.NewInt(1), 21000, gasPrice, nil).MarshalBinary()
+	for name, c := range map[string]struct {
+		raw  string
+		want string
+	}{
+		"hex":      {raw: "0xzz", want: "invalid raw transaction"},
+		"unsigned": {raw: hexutil.Encode(unsigned), want: "chain id mismatch"},
+		"chain id": {raw: sign(types.NewTransaction(0, to, big.NewInt(1), 21000, gasPrice, nil), big.NewInt(1)), want: "chain id mismatch"},
+		"calldata": {raw: sign(types.NewTransaction(0, to, big.NewInt(0), 50000, gasPrice, []byte{1, 2, 3, 4}), e.chainID), want: "invalid transaction target"},
+		"create":   {raw: sign(types.NewContractCreation(0, big.NewInt(0), 50000, gasPrice, []byte{0}), e.chainID), want: "invalid transaction target"},
+		"view":     {raw: sign(types.NewTransaction(0, e.token, big.NewInt(0), 50000, gasPrice, crypto.Keccak256([]byte("totalSupply()"))[:4]), e.chainID), want: "invalid transaction target"},
+	} {
+		w := e.requestJSON("POST", "/v1/tx/broadcast", ctl.BroadcastTxRequest{RawTx: c.raw, From: other.Hex()})
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), c.want) {
+			t.Errorf("%s status = %d, body: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	valid := sign(types.NewTransaction(0, to, big.NewInt(1), 21000, gasPrice, nil), e.chainID)
+	if w := e.requestJSON("POST", "/v1/tx/broadcast", ctl.BroadcastTxRequest{RawTx: valid}); w.Code != http.StatusBadRequest {
+		t.Errorf("broadcast without from status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	if w := e.request("POST", "/v1/coin/build", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusBadRequest {
+		t.Errorf("missing from status = %d, want 400", w.Code)
+	}
+	// 트랜잭션 생성 요청은 영수증을 기다리지 않음
+	for _, query := range []string{"wait=true", "confirmations=2", "timeout=5"} {
+		if w := e.request("POST", "/v1/token/build?"+query, map[string]string{"address": to.Hex(), "amount": "1", "from": other.Hex()}); w.Code != http.StatusBadRequest {
+			t.Errorf("build %s status = %d, body: %s", query, w.Code, w.Body.String())
+		}
+	}
+	if w := e.request("POST", "/v1/token/private", map[string]string{"address": to.Hex(), "amount": "1", "privateKey": "00"}); w.Code != http.StatusNotFound {
+		t.Errorf("removed private route status = %d, want 404", w.Code)
+	}
+}
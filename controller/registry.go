@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 토큰 등록 요청
+type AddTokenRequest struct {
+	Address string `json:"address" binding:"required"`
+}
+
+type TokenListResponse struct {
+	Tokens []model.TokenInfo `json:"tokens"`
+}
+
+// 레지스트리 요청 실패 응답
+func abortRegistryError(c *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, model.ErrUnknownToken) {
+		status, message = http.StatusNotFound, "토큰을 찾을 수 없습니다!"
+	} else if errors.Is(err, model.ErrTokenExists) {
+		status, message = http.StatusConflict, "이미 등록된 토큰입니다!"
+	} else if errors.Is(err, model.ErrAmbiguousToken) {
+		message = "같은 심볼의 토큰이 여러 개 등록되어 있어 주소로 지정해야 합니다"
+	} else if errors.Is(err, model.ErrDefaultToken) {
+		message = "기본 토큰은 제거할 수 없습니다!"
+	} else if errors.Is(err, model.ErrInvalidAddress) {
+		message = "address 정보가 유효하지 않습니다"
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func (p *Controller) ListTokensController(c *gin.Context) {
+	c.JSON(200, TokenListResponse{Tokens: p.md.ListTokensModel()})
+}
+
+func (p *Controller) SearchTokenInfoController(c *gin.Context) {
+	info, err := p.md.SearchTokenInfoModel(c.Param("token"))
+
+	if err != nil {
+		abortRegistryError(c, "토큰 정보를 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, info)
+}
+
+func (p *Controller) AddTokenController(c *gin.Context) {
+	var body AddTokenRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	info, err := p.md.AddTokenModel(body.Address)
+
+	if err != nil {
+		abortRegistryError(c, "토큰을 등록하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, info)
+}
+
+func (p *Controller) RemoveTokenController(c *gin.Context) {
+	info, err := p.md.RemoveTokenModel(c.Param("token"))
+
+	if err != nil {
+		abortRegistryError(c, "토큰을 제거하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, info)
+}
@@ -0,0 +1,117 @@
+package controller_test
+
+import (
+	"math/big"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	conf "go-contract/config"
+	cont "go-contract/contracts"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestTokenRegistryController(t *testing.T) {
+	e := newTestEnv(t)
+	second := e.deployToken()
+
+	var list ctl.TokenListResponse
+	w := e.request("GET", "/v1/tokens/", nil)
+	e.decode(w, &list)
+	if w.Code != http.StatusOK || len(list.Tokens) != 1 || !list.Tokens[0].Default || list.Tokens[0].Decimals != 18 {
+		t.Fatalf("list status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	if w := e.requestJSON("POST", "/v1/tokens/", ctl.AddTokenRequest{Address: second.Hex()}); w.Code != http.StatusOK {
+		t.Fatalf("add status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestJSON("POST", "/v1/tokens/", ctl.AddTokenRequest{Address: second.Hex()}); w.Code != http.StatusConflict {
+		t.Errorf("add duplicate status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// 같은 심볼이 두 개 등록되면 주소로 지정해야 함
+	to := newAddress()
+	if w := e.request("POST", "/v1/token/?token=YKK", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusBadRequest {
+		t.Errorf("ambiguous status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.sendSigned("/v1/token/build?token="+second.Hex(), map[string]string{
+		"address": to.Hex(),
+		"amount":  "2",
+		"unit":    "base",
+	}); w.Code != http.StatusOK {
+		t.Fatalf("send status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	caller, _ := cont.NewContractsCaller(second, e.sim)
+	if balance, _ := caller.BalanceOf(&bind.CallOpts{}, to); balance.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("second token balance = %s, want 2", balance)
+	}
+	if e.tokenBalance(to).Sign() != 0 {
+		t.Errorf("default token balance = %s, want 0", e.tokenBalance(to))
+	}
+
+	if w := e.request("DELETE", "/v1/tokens/"+e.token.Hex(), nil); w.Code != http.StatusBadRequest {
+		t.Errorf("remove default status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("DELETE", "/v1/tokens/"+second.Hex(), nil); w.Code != http.StatusOK {
+		t.Errorf("remove status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("GET", "/v1/tokens/"+second.Hex(), nil); w.Code != http.StatusNotFound {
+		t.Errorf("removed token status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("GET", "/v1/token/name?token=ykk", nil); w.Code != http.StatusOK {
+		t.Errorf("name by symbol status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTokenRegistryFromConfigController(t *testing.T) {
+	var token string
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		// 기본 토큰 주소를 [[tokens]]에 중복으로 넣어도 한 번만 등록되어야 함
+		token = cfg.Contract.TokenAddress
+		cfg.Tokens = append(cfg.Tokens, struct{ Address string }{Address: token})
+	})
+
+	var list ctl.TokenListResponse
+	w := e.request("GET", "/v1/tokens/", nil)
+	e.decode(w, &list)
+	if len(list.Tokens) != 1 || list.Tokens[0].Address != token {
+		t.Errorf("list body: %s", w.Body.String())
+	}
+}
+
+// 실행 중에 등록, 제거한 토큰은 registry path에 기록해 재시작해도 유지
+func TestTokenRegistryReloadController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Registry.Path = filepath.Join(t.TempDir(), "registry.json")
+	})
+	second, third := e.deployToken(), e.deployToken()
+	for _, token := range []string{second.Hex(), third.Hex()} {
+		if w := e.requestJSON("POST", "/v1/tokens/", ctl.AddTokenRequest{Address: token}); w.Code != http.StatusOK {
+			t.Fatalf("add status = %d, body: %s", w.Code, w.Body.String())
+		}
+	}
+	if w := e.request("DELETE", "/v1/tokens/"+third.Hex(), nil); w.Code != http.StatusOK {
+		t.Fatalf("remove status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// config에 있는 토큰도 제거한 기록이 있으면 다시 등록하지 않음
+	e.cfg.Tokens = append(e.cfg.Tokens, struct{ Address string }{Address: third.Hex()})
+	mod, err := md.NewModel(e.cfg, e.sim)
+	if err != nil {
+		t.Fatalf("NewModel Error: %s", err)
+	}
+	tokens := mod.ListTokensModel()
+	if len(tokens) != 2 {
+		t.Fatalf("tokens after reload = %+v", tokens)
+	}
+	for _, info := range tokens {
+		if info.Address != e.token.Hex() && info.Address != second.Hex() {
+			t.Errorf("unexpected token after reload: %+v", info)
+		}
+	}
+}
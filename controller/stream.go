@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"go-contract/model"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 이벤트가 없어도 연결이 끊기지 않도록 보내는 SSE 주석, websocket ping 간격
+	streamKeepAlive = 15 * time.Second
+	// websocket 쓰기 제한 시간
+	wsWriteTimeout = 10 * time.Second
+)
+
+// 다른 도메인에서도 구독할 수 있도록 CORS 설정과 같이 모든 origin 허용
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// 이벤트 구독 조건을 쿼리에서 읽어 EventStream 생성, 실패하면 응답을 쓰고 nil 반환
+// SSE 재연결시 브라우저가 보내는 Last-Event-ID 헤더를 cursor로 사용
+func (p *Controller) eventStreamFromContext(c *gin.Context) *model.EventStream {
+	q := &model.EventQuery{
+		Token:   c.Query("token"),
+		Address: c.Query("address"),
+		Cursor:  c.Query("cursor"),
+	}
+	if id := c.GetHeader("Last-Event-ID"); id != "" {
+		q.Cursor = id
+	}
+	var ok bool
+	if q.FromBlock, ok = blockQuery(c, "from_block"); !ok {
+		return nil
+	}
+
+	stream, err := p.md.StreamTokenEventsModel(q)
+	if errors.Is(err, model.ErrInvalidCursor) {
+		abortSearchError(c, "cursor 정보가 유효하지 않습니다", err)
+		return nil
+	} else if errors.Is(err, model.ErrSubscriptionUnsupported) {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"message": "이벤트 구독용 websocket rpc가 설정되지 않았습니다",
+			"error":   err.Error(),
+		})
+		return nil
+	} else if err != nil {
+		abortSearchError(c, "이벤트를 구독하지 못했습니다!", err)
+		return nil
+	}
+	return stream
+}
+
+// Transfer, Approval 이벤트를 Server-Sent Events로 전달
+// address로 관련 주소를, from_block 또는 cursor로 지난 이벤트부터 받을 위치를 지정하며 각 이벤트의 id를 cursor로 사용
+func (p *Controller) StreamTokenEventsSSEController(c *gin.Context) {
+	stream := p.eventStreamFromContext(c)
+	if stream == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+	events := make(chan model.TokenEvent, 16)
+	go stream.Run(ctx, events)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// 프록시가 응답을 모아두지 않도록 설정
+	c.Header("X-Accel-Buffering", "no")
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case ev, ok := <-events:
+			// 서버 종료로 구독이 끝나면 응답을 끝내고, 브라우저는 Last-Event-ID로 다시 연결
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{Id: ev.ID(), Event: ev.Event, Data: ev})
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// Transfer, Approval 이벤트를 websocket으로 전달, 이벤트마다 JSON 메시지 하나
+// 쿼리는 SSE와 같고 마지막으로 받은 이벤트의 id를 cursor로 넘겨 다시 연결하면 이어서 받음
+func (p *Controller) StreamTokenEventsWSController(c *gin.Context) {
+	stream := p.eventStreamFromContext(c)
+	if stream == nil {
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// upgrader가 에러 응답을 보냄
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan model.TokenEvent, 16)
+	go stream.Run(ctx, events)
+
+	// 클라이언트가 연결을 닫으면 구독 종료, 클라이언트 메시지는 사용하지 않음
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(streamKeepAlive)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsWriteTimeout))
+			return
+		case ev, ok := <-events:
+			// 서버 종료로 구독이 끝나면 클라이언트가 다시 연결하도록 닫음
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(wsWriteTimeout))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := conn.WriteJSON(ev); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// 이벤트 구독 ticket 발급, 헤더를 설정할 수 없는 브라우저 EventSource, WebSocket은 ticket 쿼리로 인증
+// ticket은 1분 안에 연결할 때만 사용할 수 있음
+func (p *Controller) IssueStreamTicketController(c *gin.Context) {
+	ticket, err := p.md.IssueStreamTicketModel(apiKeyID(c))
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"message": "구독 ticket을 발급하지 못했습니다!",
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, ticket)
+}
@@ -0,0 +1,240 @@
+package controller_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+)
+
+type sseEvent struct {
+	id    string
+	event md.TokenEvent
+}
+
+// SSE 응답을 읽어 이벤트를 채널로 전달, ctx가 끝나면 연결 종료
+func (e *testEnv) subscribeSSE(ctx context.Context, url string, lastEventID string) <-chan sseEvent {
+	e.t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		e.t.Fatalf("sse request Error: %s", err)
+	}
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		e.t.Fatalf("sse status = %d, content-type = %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer res.Body.Close()
+		defer close(events)
+		var ev sseEvent
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id:"):
+				ev.id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			case strings.HasPrefix(line, "data:"):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &ev.event)
+			case line == "" && ev.id != "":
+				events <- ev
+				ev = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("event stream closed")
+		}
+		return ev
+	case <-time.After(10 * time.Second):
+		t.Fatal("event timeout")
+	}
+	return sseEvent{}
+}
+
+func TestStreamTokenEventsSSEController(t *testing.T) {
+	e := newTestEnv(t)
+	srv := httptest.NewServer(e.engine)
+	defer srv.Close()
+
+	to := newAddress()
+	from := strconv.FormatUint(e.sim.Blockchain().CurrentBlock().NumberU64()+1, 10)
+	url := srv.URL + "/v1/token/events/sse?address=" + to.Hex()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := e.subscribeSSE(ctx, url+"&from_block="+from, "")
+
+	// 다른 주소로의 전송은 전달되지 않아야 함
+	for _, req := range []map[string]string{
+		{"address": to.Hex(), "amount": "1"},
+		{"address": newAddress().Hex(), "amount": "2"},
+		{"address": to.Hex(), "amount": "3"},
+	} {
+		if w := e.request("POST", "/v1/token/", req); w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+		}
+		e.sim.Commit()
+	}
+
+	first := nextEvent(t, events)
+	second := nextEvent(t, events)
+	cancel()
+	if first.event.Event != md.EventTransfer || first.event.To != to.Hex() || first.event.Value != ether("1000000000000000000").String() || first.event.Token != e.token.Hex() {
+		t.Errorf("first event = %+v", first.event)
+	}
+	if second.event.Value != ether("3000000000000000000").String() || second.id != second.event.ID() || second.event.BlockNumber <= first.event.BlockNumber {
+		t.Errorf("second event = %+v", second)
+	}
+
+	// 마지막으로 받은 이벤트 id로 다시 연결하면 그 다음 이벤트부터 전달
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events = e.subscribeSSE(ctx, url, first.id)
+	if resumed := nextEvent(t, events); resumed.id != second.id {
+		t.Errorf("resumed event = %+v, want %s", resumed, second.id)
+	}
+}
+
+func TestStreamTokenEventsWSController(t *testing.T) {
+	e := newTestEnv(t)
+	srv := httptest.NewServer(e.engine)
+	defer srv.Close()
+
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	to := newAddress()
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "1"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/token/events/ws?from_block=0", nil)
+	if err != nil {
+		t.Fatalf("websocket dial Error: %s", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	// 연결 전의 전송부터 전달
+	var ev md.TokenEvent
+	if err := conn.ReadJSON(&ev); err != nil {
+		t.Fatalf("websocket read Error: %s", err)
+	}
+	if ev.Event != md.EventTransfer || ev.From != owner.Hex() || ev.To != to.Hex() {
+		t.Errorf("transfer event = %+v", ev)
+	}
+
+	spender := newAddress()
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: spender.Hex(), Amount: "5"}); w.Code != http.StatusOK {
+		t.Fatalf("approve status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	ev = md.TokenEvent{}
+	if err := conn.ReadJSON(&ev); err != nil {
+		t.Fatalf("websocket read Error: %s", err)
+	}
+	if ev.Event != md.EventApproval || ev.Owner != owner.Hex() || ev.Spender != spender.Hex() || ev.Value != ether("5000000000000000000").String() {
+		t.Errorf("approval event = %+v", ev)
+	}
+}
+
+func TestStreamTokenEventsControllerValidation(t *testing.T) {
+	e := newTestEnv(t)
+
+	for _, query := range []string{"?address=0x1234", "?cursor=abc", "?from_block=-1", "?token=ABC"} {
+		if w := e.request("GET", "/v1/token/events/sse"+query, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, body: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
+
+// 인증을 사용하면 헤더 대신 발급받은 ticket 쿼리로도 구독
+func TestStreamTokenEventsTicketController(t *testing.T) {
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Auth.Enabled = true
+		cfg.Auth.Path = filepath.Join(t.TempDir(), "apikeys.json")
+	})
+	srv := httptest.NewServer(e.engine)
+	defer srv.Close()
+	admin, err := md.IssueAPIKey(e.cfg, "admin", []string{md.ScopeAdmin})
+	if err != nil {
+		t.Fatalf("IssueAPIKey Error: %s", err)
+	}
+	reader, err := md.IssueAPIKey(e.cfg, "reader", []string{md.ScopeTokenRead})
+	if err != nil {
+		t.Fatalf("IssueAPIKey Error: %s", err)
+	}
+
+	for _, query := range []string{"", "?ticket=abc", "?api_key=" + reader.Key} {
+		if w := e.request("GET", "/v1/token/events/sse"+query, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("%q status = %d, body: %s", query, w.Code, w.Body.String())
+		}
+	}
+	// ticket은 구독 경로에서만 사용
+	if w := e.requestWithKey("POST", "/v1/token/events/ticket", "", nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("ticket without key status = %d, body: %s", w.Code, w.Body.String())
+	}
+	w := e.requestWithKey("POST", "/v1/token/events/ticket", reader.Key, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ticket status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var ticket md.StreamTicket
+	e.decode(w, &ticket)
+	if ticket.Ticket == "" || !ticket.ExpiresAt.After(time.Now()) {
+		t.Fatalf("ticket = %+v", ticket)
+	}
+	if w := e.request("GET", "/v1/token/name?ticket="+ticket.Ticket, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("ticket on name status = %d, want 401", w.Code)
+	}
+
+	// 연결 전의 전송부터 전달
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	if w := e.requestWithKey("POST", "/v1/token/", admin.Key, map[string]string{"address": newAddress().Hex(), "amount": "1"}, nil); w.Code != http.StatusOK {
+		t.Fatalf("send status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := e.subscribeSSE(ctx, srv.URL+"/v1/token/events/sse?from_block=0&ticket="+ticket.Ticket, "")
+	if ev := nextEvent(t, events); ev.event.From != owner.Hex() {
+		t.Errorf("event = %+v", ev)
+	}
+	cancel()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/token/events/ws?ticket="+ticket.Ticket, nil)
+	if err != nil {
+		t.Fatalf("websocket dial Error: %s", err)
+	}
+	conn.Close()
+
+	// ticket을 발급한 key가 폐기되면 ticket도 사용할 수 없음
+	if w := e.requestWithKey("DELETE", "/v1/apikeys/"+reader.ID, admin.Key, nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("GET", "/v1/token/events/sse?ticket="+ticket.Ticket, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked ticket status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 토큰 사용 권한 부여 요청
+type ApproveRequest struct {
+	Spender string `json:"spender" binding:"required"`
+	Amount  string `json:"amount" binding:"required"`
+	Unit    string `json:"unit"`
+}
+
+// 부여받은 사용 권한으로 전송 요청
+type TransferFromRequest struct {
+	From   string `json:"from" binding:"required"`
+	To     string `json:"to" binding:"required"`
+	Amount string `json:"amount" binding:"required"`
+	Unit   string `json:"unit"`
+}
+
+// 토큰 발행, 소각 요청
+type SupplyRequest struct {
+	Amount string `json:"amount" binding:"required"`
+	Unit   string `json:"unit"`
+}
+
+type NameResponse struct {
+	Name string `json:"name"`
+}
+
+type DecimalsResponse struct {
+	Decimals uint8 `json:"decimals"`
+}
+
+type TotalSupplyResponse struct {
+	TotalSupply string `json:"totalSupply"`
+}
+
+type AllowanceResponse struct {
+	Owner     string `json:"owner"`
+	Spender   string `json:"spender"`
+	Allowance string `json:"allowance"`
+}
+
+// json 요청 body 바인딩, 필수 값이 없으면 400 응답
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": "요청 정보가 유효하지 않습니다",
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// 토큰 조회 실패 응답, 주소 형식 오류와 없는 토큰은 별도 메시지로 구분
+func abortSearchError(c *gin.Context, message string, err error) {
+	if errors.Is(err, model.ErrInvalidAddress) {
+		message = "address 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrUnknownToken) || errors.Is(err, model.ErrAmbiguousToken) {
+		message = "token 정보가 유효하지 않습니다"
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func (p *Controller) SearchTokenNameController(c *gin.Context) {
+	name, err := p.md.SearchTokenNameModel(c.Query("token"))
+
+	if err != nil {
+		abortSearchError(c, "name을 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, NameResponse{Name: name})
+}
+
+func (p *Controller) SearchTokenDecimalsController(c *gin.Context) {
+	decimals, err := p.md.SearchTokenDecimalsModel(c.Query("token"))
+
+	if err != nil {
+		abortSearchError(c, "decimals를 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, DecimalsResponse{Decimals: decimals})
+}
+
+func (p *Controller) SearchTokenTotalSupplyController(c *gin.Context) {
+	totalSupply, err := p.md.SearchTokenTotalSupplyModel(c.Query("token"))
+
+	if err != nil {
+		abortSearchError(c, "totalSupply를 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, TotalSupplyResponse{TotalSupply: totalSupply.String()})
+}
+
+func (p *Controller) SearchTokenAllowanceController(c *gin.Context) {
+	owner, spender := c.Query("owner"), c.Query("spender")
+	allowance, err := p.md.SearchTokenAllowanceModel(c.Query("token"), owner, spender)
+
+	if err != nil {
+		abortSearchError(c, "allowance를 가져오지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, AllowanceResponse{Owner: owner, Spender: spender, Allowance: allowance.String()})
+}
+
+func (p *Controller) ApproveTokenController(c *gin.Context) {
+	var body ApproveRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	req := &model.SendRequest{TargetAddress: body.Spender, Amount: body.Amount, Token: c.Query("token"), APIKey: apiKeyID(c)}
+	if !applyUnit(c, req, body.Unit) || !applyWaitOption(c, req) {
+		return
+	}
+	result, err := p.md.ApproveTokenModel(req)
+
+	if err != nil {
+		abortSendError(c, result, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func (p *Controller) TransferTokenFromController(c *gin.Context) {
+	var body TransferFromRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	req := &model.SendRequest{TargetAddress: body.To, Amount: body.Amount, Token: c.Query("token"), APIKey: apiKeyID(c)}
+	if !applyUnit(c, req, body.Unit) || !applyWaitOption(c, req) {
+		return
+	}
+	result, err := p.md.TransferTokenFromModel(req, body.From)
+
+	if err != nil {
+		abortSendError(c, result, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func (p *Controller) MintTokenController(c *gin.Context) {
+	var body SupplyRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	req := &model.SendRequest{Amount: body.Amount, Token: c.Query("token")}
+	if !applyUnit(c, req, body.Unit) || !applyWaitOption(c, req) {
+		return
+	}
+	result, err := p.md.MintTokenModel(req)
+
+	if err != nil {
+		abortSendError(c, result, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func (p *Controller) BurnTokenController(c *gin.Context) {
+	var body SupplyRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	req := &model.SendRequest{Amount: body.Amount, Token: c.Query("token")}
+	if !applyUnit(c, req, body.Unit) || !applyWaitOption(c, req) {
+		return
+	}
+	result, err := p.md.BurnTokenModel(req)
+
+	if err != nil {
+		abortSendError(c, result, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
@@ -0,0 +1,127 @@
+package controller_test
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+
+	cont "go-contract/contracts"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSearchTokenMetadataController(t *testing.T) {
+	e := newTestEnv(t)
+
+	var name ctl.NameResponse
+	w := e.request("GET", "/v1/token/name", nil)
+	e.decode(w, &name)
+	if w.Code != http.StatusOK || name.Name != "YKK Token" {
+		t.Errorf("name status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var decimals ctl.DecimalsResponse
+	w = e.request("GET", "/v1/token/decimals", nil)
+	e.decode(w, &decimals)
+	if w.Code != http.StatusOK || decimals.Decimals != 18 {
+		t.Errorf("decimals status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	var supply ctl.TotalSupplyResponse
+	w = e.request("GET", "/v1/token/totalSupply", nil)
+	e.decode(w, &supply)
+	if w.Code != http.StatusOK || supply.TotalSupply != ether("1000000000000000000000000000").String() {
+		t.Errorf("totalSupply status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestApproveAndTransferFromController(t *testing.T) {
+	e := newTestEnv(t)
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	spender := newAddress()
+
+	w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: spender.Hex(), Amount: "5"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("approve status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var res md.SendResult
+	e.decode(w, &res)
+	e.sim.Commit()
+	if res.Method != "approve" || res.To != spender.Hex() {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	var allowance ctl.AllowanceResponse
+	w = e.request("GET", "/v1/token/allowance?owner="+owner.Hex()+"&spender="+spender.Hex(), nil)
+	e.decode(w, &allowance)
+	if w.Code != http.StatusOK || allowance.Allowance != ether("5000000000000000000").String() {
+		t.Errorf("allowance status = %d, body: %s", w.Code, w.Body.String())
+	}
+
+	// other 계정이 서비스 지갑에 사용 권한을 주면 서비스 지갑이 대신 전송
+	other := crypto.PubkeyToAddress(e.other.PublicKey)
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": other.Hex(), "amount": "10"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	auth, _ := bind.NewKeyedTransactorWithChainID(e.other, e.chainID)
+	transactor, _ := cont.NewContractsTransactor(e.token, e.sim)
+	if _, err := transactor.Approve(auth, owner, big.NewInt(7)); err != nil {
+		t.Fatalf("Approve Error: %s", err)
+	}
+	e.sim.Commit()
+
+	to := newAddress()
+	w = e.requestJSON("POST", "/v1/token/transferFrom", ctl.TransferFromRequest{From: other.Hex(), To: to.Hex(), Amount: "7", Unit: "base"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("transferFrom status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.decode(w, &res)
+	e.sim.Commit()
+	if res.Sender != other.Hex() || res.From != owner.Hex() || e.tokenBalance(to).Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("unexpected result: %+v, balance %s", res, e.tokenBalance(to))
+	}
+
+	// 남은 권한을 넘는 전송은 revert 예상
+	w = e.requestJSON("POST", "/v1/token/transferFrom", ctl.TransferFromRequest{From: other.Hex(), To: to.Hex(), Amount: "1", Unit: "base"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMintAndBurnController(t *testing.T) {
+	e := newTestEnv(t)
+	owner := crypto.PubkeyToAddress(e.owner.PublicKey)
+	before := e.tokenBalance(owner)
+
+	if w := e.requestJSON("POST", "/v1/token/mint", ctl.SupplyRequest{Amount: "100"}); w.Code != http.StatusOK {
+		t.Fatalf("mint status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	if w := e.requestJSON("POST", "/v1/token/burn", ctl.SupplyRequest{Amount: "40"}); w.Code != http.StatusOK {
+		t.Fatalf("burn status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	want := new(big.Int).Add(before, ether("60000000000000000000"))
+	if e.tokenBalance(owner).Cmp(want) != 0 {
+		t.Errorf("balance = %s, want %s", e.tokenBalance(owner), want)
+	}
+}
+
+func TestTokenRequestValidationController(t *testing.T) {
+	e := newTestEnv(t)
+
+	if w := e.requestJSON("POST", "/v1/token/approve", ctl.ApproveRequest{Spender: "0x1234", Amount: "1"}); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid spender status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.requestJSON("POST", "/v1/token/mint", map[string]string{}); w.Code != http.StatusBadRequest {
+		t.Errorf("missing amount status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("GET", "/v1/token/allowance?owner=abc&spender=def", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid owner status = %d, body: %s", w.Code, w.Body.String())
+	}
+}
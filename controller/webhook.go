@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"errors"
+	"go-contract/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhook 등록 요청, secret을 생략하면 생성해 응답에 포함
+type AddWebhookRequest struct {
+	URL       string   `json:"url" binding:"required"`
+	Addresses []string `json:"addresses" binding:"required"`
+	Secret    string   `json:"secret"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []model.Webhook `json:"webhooks"`
+}
+
+type DeadLetterListResponse struct {
+	DeadLetters []model.DeadLetter `json:"deadLetters"`
+}
+
+// webhook 요청 실패 응답
+func abortWebhookError(c *gin.Context, message string, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, model.ErrUnknownWebhook) {
+		status, message = http.StatusNotFound, "webhook을 찾을 수 없습니다!"
+	} else if errors.Is(err, model.ErrUnknownDeadLetter) {
+		status, message = http.StatusNotFound, "dead letter를 찾을 수 없습니다!"
+	} else if errors.Is(err, model.ErrInvalidWebhookURL) {
+		message = "url 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrInvalidAddress) {
+		message = "addresses 정보가 유효하지 않습니다"
+	} else if errors.Is(err, model.ErrDeliveryFailed) {
+		status = http.StatusBadGateway
+	} else {
+		status = http.StatusInternalServerError
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func (p *Controller) ListWebhooksController(c *gin.Context) {
+	c.JSON(200, WebhookListResponse{Webhooks: p.md.ListWebhooksModel()})
+}
+
+// addresses로 들어오는 토큰, 코인 입금을 url로 알리는 webhook 등록
+func (p *Controller) AddWebhookController(c *gin.Context) {
+	var body AddWebhookRequest
+	if !bindJSON(c, &body) {
+		return
+	}
+	hook, err := p.md.AddWebhookModel(&model.WebhookRequest{
+		URL:       body.URL,
+		Addresses: body.Addresses,
+		Secret:    body.Secret,
+	})
+
+	if err != nil {
+		abortWebhookError(c, "webhook을 등록하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, hook)
+}
+
+func (p *Controller) RemoveWebhookController(c *gin.Context) {
+	hook, err := p.md.RemoveWebhookModel(c.Param("id"))
+
+	if err != nil {
+		abortWebhookError(c, "webhook을 삭제하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, hook)
+}
+
+func (p *Controller) ListDeadLettersController(c *gin.Context) {
+	c.JSON(200, DeadLetterListResponse{DeadLetters: p.md.ListDeadLettersModel()})
+}
+
+// 전송하지 못한 알림을 다시 전송, 성공하면 dead letter 목록에서 제거
+func (p *Controller) ReplayDeadLetterController(c *gin.Context) {
+	letter, err := p.md.ReplayDeadLetterModel(c.Param("id"))
+
+	if err != nil {
+		abortWebhookError(c, "다시 전송하지 못했습니다!", err)
+		return
+	}
+
+	c.JSON(200, letter)
+}
@@ -0,0 +1,201 @@
+package controller_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+	ctl "go-contract/controller"
+	md "go-contract/model"
+)
+
+// webhook callback 서버, fail이 true인 동안 500으로 응답
+type webhookReceiver struct {
+	mu       sync.Mutex
+	fail     bool
+	payloads []map[string]interface{}
+	srv      *httptest.Server
+}
+
+func newWebhookReceiver(t *testing.T, secret *string) *webhookReceiver {
+	r := &webhookReceiver{}
+	r.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		mac := hmac.New(sha256.New, []byte(*secret))
+		mac.Write([]byte(req.Header.Get(md.WebhookTimestampHeader) + "."))
+		mac.Write(body)
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.Header.Get(md.WebhookSignatureHeader) != want {
+			t.Errorf("signature = %s, want %s", req.Header.Get(md.WebhookSignatureHeader), want)
+		}
+		if r.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var payload map[string]interface{}
+		json.Unmarshal(body, &payload)
+		r.payloads = append(r.payloads, payload)
+	}))
+	t.Cleanup(r.srv.Close)
+	return r
+}
+
+func (r *webhookReceiver) setFail(fail bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = fail
+}
+
+func (r *webhookReceiver) received() []map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]interface{}{}, r.payloads...)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s timeout", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestWebhookController(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhook.json")
+	e := newTestEnv(t, func(cfg *conf.Config) {
+		cfg.Webhook.Path = path
+		cfg.Webhook.Confirmations = 2
+		cfg.Webhook.PollInterval = 1
+		cfg.Webhook.MaxAttempts = 2
+	})
+
+	var okSecret, failSecret string
+	okHook := newWebhookReceiver(t, &okSecret)
+	failHook := newWebhookReceiver(t, &failSecret)
+	failHook.setFail(true)
+
+	to := newAddress()
+	register := func(url string, secret string) md.Webhook {
+		t.Helper()
+		w := e.requestJSON("POST", "/v1/webhooks/", ctl.AddWebhookRequest{URL: url, Addresses: []string{to.Hex()}, Secret: secret})
+		if w.Code != http.StatusOK {
+			t.Fatalf("register status = %d, body: %s", w.Code, w.Body.String())
+		}
+		var hook md.Webhook
+		e.decode(w, &hook)
+		return hook
+	}
+	okSecret = register(okHook.srv.URL, "").Secret
+	failSecret = "fail-secret"
+	failed := register(failHook.srv.URL, failSecret)
+	if okSecret == "" || failed.Secret != failSecret {
+		t.Fatalf("secrets = %q, %q", okSecret, failed.Secret)
+	}
+
+	var list ctl.WebhookListResponse
+	e.decode(e.request("GET", "/v1/webhooks/", nil), &list)
+	if len(list.Webhooks) != 2 || list.Webhooks[0].Secret != "" || list.Webhooks[0].Addresses[0] != to.Hex() {
+		t.Errorf("webhooks = %+v", list.Webhooks)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.mod.RunWebhooks(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// 첫 확인에서 시작 블록이 정해진 뒤 입금
+	waitFor(t, "start block", func() bool {
+		var state struct{ LastBlock *uint64 }
+		data, _ := ioutil.ReadFile(path)
+		return json.Unmarshal(data, &state) == nil && state.LastBlock != nil
+	})
+
+	if w := e.request("POST", "/v1/token/", map[string]string{"address": to.Hex(), "amount": "2"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+	if w := e.request("POST", "/v1/coin/", map[string]string{"address": to.Hex(), "amount": "0.5"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+	}
+	e.sim.Commit()
+
+	// 두 번째 입금은 확인 블록이 쌓여야 알림
+	time.Sleep(1500 * time.Millisecond)
+	if got := okHook.received(); len(got) != 1 || got[0]["type"] != md.DepositToken || got[0]["value"] != ether("2000000000000000000").String() {
+		t.Fatalf("payloads before confirmation = %+v", got)
+	}
+	e.sim.Commit()
+	waitFor(t, "coin deposit", func() bool { return len(okHook.received()) == 2 })
+	if got := okHook.received()[1]; got["type"] != md.DepositCoin || got["to"] != to.Hex() || got["value"] != ether("500000000000000000").String() {
+		t.Errorf("coin payload = %+v", got)
+	}
+
+	// 실패한 전송은 maxAttempts번 시도 후 dead letter로 이동
+	var letters ctl.DeadLetterListResponse
+	waitFor(t, "dead letters", func() bool {
+		e.decode(e.request("GET", "/v1/webhooks/deadletters", nil), &letters)
+		return len(letters.DeadLetters) == 2
+	})
+	if letters.DeadLetters[0].WebhookID != failed.ID || letters.DeadLetters[0].Attempts != 2 {
+		t.Errorf("dead letter = %+v", letters.DeadLetters[0])
+	}
+
+	// 서버가 복구되면 replay로 다시 전송
+	if w := e.request("POST", "/v1/webhooks/deadletters/"+letters.DeadLetters[0].ID+"/replay", nil); w.Code != http.StatusBadGateway {
+		t.Errorf("replay to failing server status = %d, body: %s", w.Code, w.Body.String())
+	}
+	failHook.setFail(false)
+	for _, letter := range letters.DeadLetters {
+		if w := e.request("POST", "/v1/webhooks/deadletters/"+letter.ID+"/replay", nil); w.Code != http.StatusOK {
+			t.Errorf("replay status = %d, body: %s", w.Code, w.Body.String())
+		}
+	}
+	e.decode(e.request("GET", "/v1/webhooks/deadletters", nil), &letters)
+	if len(letters.DeadLetters) != 0 || len(failHook.received()) != 2 {
+		t.Errorf("dead letters after replay = %+v", letters.DeadLetters)
+	}
+
+	if w := e.request("DELETE", "/v1/webhooks/"+failed.ID, nil); w.Code != http.StatusOK {
+		t.Errorf("delete status = %d, body: %s", w.Code, w.Body.String())
+	}
+	if w := e.request("DELETE", "/v1/webhooks/"+failed.ID, nil); w.Code != http.StatusNotFound {
+		t.Errorf("delete again status = %d, want 404", w.Code)
+	}
+}
+
+func TestAddWebhookControllerValidation(t *testing.T) {
+	e := newTestEnv(t)
+
+	for _, body := range []ctl.AddWebhookRequest{
+		{URL: "ftp://example.com", Addresses: []string{newAddress().Hex()}},
+		{URL: "http://example.com", Addresses: []string{"0x1234"}},
+		{URL: "http://example.com", Addresses: []string{}},
+	} {
+		if w := e.requestJSON("POST", "/v1/webhooks/", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%+v status = %d, body: %s", body, w.Code, w.Body.String())
+		}
+	}
+	if w := e.request("POST", "/v1/webhooks/deadletters/unknown/replay", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown dead letter status = %d, want 404", w.Code)
+	}
+}
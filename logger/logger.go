@@ -86,6 +86,11 @@ func Fatal(ctx ...interface{}) {
 	lg.Fatal("fatal", loggingData(ctx))
 }
 
+// 구조화된 필드와 함께 info 로그 기록
+func InfoFields(msg string, fields ...zap.Field) {
+	lg.Info(msg, fields...)
+}
+
 // encoder 옵션 설정
 func getEncoder() zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	conf "go-contract/config"
@@ -12,6 +13,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,6 +25,9 @@ var (
 	g errgroup.Group
 )
 
+// 조회 요청의 응답 쓰기 제한 시간
+const writeTimeout = 10 * time.Second
+
 func main() {
 
 	// 초기 keystore 생성을 위한 구문
@@ -34,6 +40,10 @@ func main() {
 	*/
 
 	var configFlag = flag.String("config", "./config/config.toml", "toml file to use for configuration")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-config path] [deploy | keygen -name name -scopes scope,...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	if cf, err := conf.NewConfig(*configFlag); err != nil { // config 모듈 설정
@@ -42,7 +52,17 @@ func main() {
 	} else if err := log.InitLogger(cf); err != nil { // logger 모듈 설정
 		fmt.Printf("init logger failed, err:%v\n", err)
 		return
-	} else if mod, err := md.NewModel(cf); err != nil { // model 모듈 설정
+	} else if flag.Arg(0) == "keygen" { // 서버를 띄우지 않고 API key 발급 후 종료
+		if err := keygen(cf, flag.Args()[1:]); err != nil {
+			fmt.Printf("keygen Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if flag.Arg(0) == "deploy" { // 서버를 띄우지 않고 컨트랙트 배포 후 종료
+		if err := deploy(cf); err != nil {
+			fmt.Printf("deploy Error: %v\n", err)
+			os.Exit(1)
+		}
+	} else if mod, err := md.NewModel(cf, nil); err != nil { // model 모듈 설정
 		fmt.Printf("NewModel Error: %v\n", err)
 	} else if controller, err := ctl.NewCTL(mod); err != nil { //controller 모듈 설정
 		fmt.Printf("NewCTL Error: %v\n", err)
@@ -50,17 +70,28 @@ func main() {
 		fmt.Printf("NewRouter Error: %v\n", err)
 	} else {
 		mapi := &http.Server{
-			Addr:           cf.Server.Port,
-			Handler:        rt.Idx(),
+			Addr: cf.Server.Port,
+			// 이벤트 스트리밍(SSE) 응답은 구독하는 동안 계속 쓰므로 WriteTimeout 대신 경로별로 쓰기 제한 시간 적용
+			// 영수증 대기 요청은 최대 대기 시간보다 길게 잡아 504 응답을 쓸 수 있도록 함
+			Handler:        rt.Handler(writeTimeout, mod.MaxWaitTimeout()+writeTimeout),
 			ReadTimeout:    5 * time.Second,
-			WriteTimeout:   10 * time.Second,
 			MaxHeaderBytes: 1 << 20,
 		}
 		g.Go(func() error {
 			return mapi.ListenAndServe()
 		})
+		// 입금 알림 webhook과 이벤트 색인, 종료시 진행 중인 작업을 정리한 뒤 반환
+		bgCtx, stopBackground := context.WithCancel(context.Background())
+		var background sync.WaitGroup
+		for _, run := range []func(context.Context){mod.RunWebhooks, mod.RunIndexer} {
+			background.Add(1)
+			go func(run func(context.Context)) {
+				defer background.Done()
+				run(bgCtx)
+			}(run)
+		}
 
-		stopSig := make(chan os.Signal)
+		stopSig := make(chan os.Signal, 1)
 		signal.Notify(stopSig, syscall.SIGINT, syscall.SIGTERM)
 		<-stopSig
 
@@ -70,6 +101,10 @@ func main() {
 		if err := mapi.Shutdown(ctx); err != nil {
 			fmt.Println("Server Shutdown Error:", err)
 		}
+		stopBackground()
+		background.Wait()
+		// 처리중인 요청이 끝난 뒤 rpc 클라이언트 정리
+		mod.Close()
 
 		select {
 		case <-ctx.Done():
@@ -82,3 +117,35 @@ func main() {
 		fmt.Println(err)
 	}
 }
+
+// config의 서비스 지갑으로 YKKToken을 배포하고 결과 출력
+// 실행 중인 서버와 함께 사용할 수 있도록 색인 저장소나 webhook 상태를 열지 않는 Model 사용
+func deploy(cf *conf.Config) error {
+	mod, err := md.NewDeployModel(cf, nil)
+	if err != nil {
+		return err
+	}
+	defer mod.Close()
+	result, err := mod.DeployTokenModel(nil)
+	if result != nil {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	}
+	return err
+}
+
+// config의 auth path 파일에 API key를 발급하고 결과 출력, 첫 admin key 발급에 사용
+func keygen(cf *conf.Config, args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	name := fs.String("name", "admin", "name of the api key")
+	scopes := fs.String("scopes", md.ScopeAdmin, "comma separated scopes (token:read, token:send, coin:read, coin:send, admin)")
+	fs.Parse(args)
+
+	issued, err := md.IssueAPIKey(cf, *name, strings.Split(*scopes, ","))
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(issued, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"context"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// 계정 정보, 최신 블록 기준으로 조회
+// PendingNonce - ConfirmedNonce는 아직 블록에 포함되지 않은 트랜잭션 수
+type AccountInfo struct {
+	Address        string `json:"address"`
+	BlockNumber    uint64 `json:"blockNumber"`
+	Balance        string `json:"balance"`
+	PendingNonce   uint64 `json:"pendingNonce"`
+	ConfirmedNonce uint64 `json:"confirmedNonce"`
+	PendingCount   uint64 `json:"pendingCount"`
+	IsContract     bool   `json:"isContract"`
+	Token          string `json:"token"`
+	TokenBalance   string `json:"tokenBalance"`
+}
+
+// address의 WEMIX 잔액, nonce, 컨트랙트 여부와 token 잔액을 한 번에 조회
+// pending nonce 외에는 같은 블록에서 조회
+func (p *Model) SearchAccountModel(token string, address string) (*AccountInfo, error) {
+	targetAddress, err := parseAddress("address", address)
+	if err != nil {
+		return nil, err
+	}
+	instance, info, err := p.tokenCaller(token)
+	if err != nil {
+		return nil, err
+	}
+	client := p.backend()
+	ctx := context.Background()
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Error("HeaderByNumber 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	block := header.Number
+
+	balance, err := client.BalanceAt(ctx, targetAddress, block)
+	if err != nil {
+		log.Error("BalanceAt 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	confirmed, err := client.NonceAt(ctx, targetAddress, block)
+	if err != nil {
+		log.Error("NonceAt 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	pending, err := client.PendingNonceAt(ctx, targetAddress)
+	if err != nil {
+		log.Error("PendingNonceAt 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	code, err := client.CodeAt(ctx, targetAddress, block)
+	if err != nil {
+		log.Error("CodeAt 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	tokenBalance, err := instance.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: block}, targetAddress)
+	if err != nil {
+		log.Error("balance 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	account := &AccountInfo{
+		Address:        targetAddress.Hex(),
+		BlockNumber:    block.Uint64(),
+		Balance:        bigString(balance),
+		PendingNonce:   pending,
+		ConfirmedNonce: confirmed,
+		IsContract:     len(code) > 0,
+		Token:          info.Address,
+		TokenBalance:   bigString(tokenBalance),
+	}
+	if pending > confirmed {
+		account.PendingCount = pending - confirmed
+	}
+	return account, nil
+}
@@ -0,0 +1,220 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	conf "go-contract/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 주소 목록 이름
+const (
+	AddressListAllow = "allow"
+	AddressListDeny  = "deny"
+)
+
+// 차단 사유
+const (
+	BlockZeroAddress   = "zero address"
+	BlockTokenContract = "token contract"
+	BlockDenylist      = "denylist"
+	BlockNotAllowed    = "not in allowlist"
+)
+
+var (
+	// 전송할 수 없는 주소인 경우 반환
+	ErrBlockedAddress = errors.New("blocked address")
+	// allow, deny 외의 목록 이름인 경우 반환
+	ErrInvalidAddressList = errors.New("invalid address list")
+	// 목록에 없는 주소를 삭제하는 경우 반환
+	ErrUnknownAddressRule = errors.New("unknown address rule")
+)
+
+// allow 또는 deny 목록에 등록한 주소
+// Source는 config의 deny 목록이면 config, 실행 중에 등록했으면 api
+type AddressRule struct {
+	Address   string    `json:"address"`
+	List      string    `json:"list"`
+	Note      string    `json:"note,omitempty"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// 주소 목록 조회 결과
+type AddressLists struct {
+	AllowlistOnly bool          `json:"allowlistOnly"`
+	Allow         []AddressRule `json:"allow"`
+	Deny          []AddressRule `json:"deny"`
+}
+
+// 서비스 지갑 전송 대상 주소 제한
+type addressListManager struct {
+	mu            sync.Mutex
+	path          string
+	allowlistOnly bool
+	configDeny    map[common.Address]bool
+	rules         []*AddressRule // 실행 중에 등록한 목록, path 파일에 기록
+}
+
+func newAddressListManager(cfg *conf.Config) (*addressListManager, error) {
+	m := &addressListManager{
+		path:          cfg.AddressList.Path,
+		allowlistOnly: cfg.AddressList.AllowlistOnly,
+		configDeny:    make(map[common.Address]bool),
+	}
+	for _, address := range cfg.AddressList.Deny {
+		addr, err := parseAddress("addressList.deny", address)
+		if err != nil {
+			return nil, err
+		}
+		m.configDeny[addr] = true
+	}
+	if m.path != "" {
+		if err := readJSON(m.path, &m.rules); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// 호출하는 쪽에서 mu를 잡고 있어야 함
+func (m *addressListManager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	return writeJSON(m.path, m.rules)
+}
+
+// 호출하는 쪽에서 mu를 잡고 있어야 함
+func (m *addressListManager) find(list string, addr common.Address) int {
+	for i, rule := range m.rules {
+		if rule.List == list && rule.Address == addr.Hex() {
+			return i
+		}
+	}
+	return -1
+}
+
+// 전송 대상 주소 확인, zero address, 등록된 토큰 컨트랙트, deny 목록 순으로 차단
+// allowlistOnly면 allow 목록에 없는 주소도 차단
+func (m *addressListManager) check(to common.Address, tokens *tokenRegistry) error {
+	reason := ""
+	if to == (common.Address{}) {
+		reason = BlockZeroAddress
+	} else if tokens.has(to.Hex()) {
+		reason = BlockTokenContract
+	} else {
+		m.mu.Lock()
+		if m.configDeny[to] || m.find(AddressListDeny, to) >= 0 {
+			reason = BlockDenylist
+		} else if m.allowlistOnly && m.find(AddressListAllow, to) < 0 {
+			reason = BlockNotAllowed
+		}
+		m.mu.Unlock()
+	}
+	if reason != "" {
+		return fmt.Errorf("%w: %s %s", ErrBlockedAddress, to.Hex(), reason)
+	}
+	return nil
+}
+
+func (m *addressListManager) lists() *AddressLists {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := &AddressLists{AllowlistOnly: m.allowlistOnly, Allow: []AddressRule{}, Deny: []AddressRule{}}
+	var configDeny []string
+	for addr := range m.configDeny {
+		configDeny = append(configDeny, addr.Hex())
+	}
+	sort.Strings(configDeny)
+	for _, address := range configDeny {
+		result.Deny = append(result.Deny, AddressRule{Address: address, List: AddressListDeny, Source: "config"})
+	}
+	for _, rule := range m.rules {
+		if rule.List == AddressListAllow {
+			result.Allow = append(result.Allow, *rule)
+		} else {
+			result.Deny = append(result.Deny, *rule)
+		}
+	}
+	return result
+}
+
+// 목록에 주소 등록, 이미 있으면 note만 갱신
+func (m *addressListManager) add(list string, address string, note string) (*AddressRule, error) {
+	if list != AddressListAllow && list != AddressListDeny {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddressList, list)
+	}
+	addr, err := parseAddress("address", address)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.find(list, addr); i >= 0 {
+		prev := m.rules[i].Note
+		m.rules[i].Note = note
+		if err := m.save(); err != nil {
+			m.rules[i].Note = prev
+			return nil, err
+		}
+		rule := *m.rules[i]
+		return &rule, nil
+	}
+	rule := &AddressRule{Address: addr.Hex(), List: list, Note: note, Source: "api", CreatedAt: time.Now().UTC()}
+	m.rules = append(m.rules, rule)
+	if err := m.save(); err != nil {
+		m.rules = m.rules[:len(m.rules)-1]
+		return nil, err
+	}
+	result := *rule
+	return &result, nil
+}
+
+// 실행 중에 등록한 주소 삭제, config의 deny 목록은 삭제할 수 없음
+func (m *addressListManager) remove(list string, address string) (*AddressRule, error) {
+	if list != AddressListAllow && list != AddressListDeny {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddressList, list)
+	}
+	addr, err := parseAddress("address", address)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.find(list, addr)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrUnknownAddressRule, list, addr.Hex())
+	}
+	rule := m.rules[i]
+	m.rules = append(m.rules[:i], m.rules[i+1:]...)
+	if err := m.save(); err != nil {
+		m.rules = append(m.rules[:i], append([]*AddressRule{rule}, m.rules[i:]...)...)
+		return nil, err
+	}
+	return rule, nil
+}
+
+// 서비스 지갑에서 to로 보낼 수 있는지 확인
+func (p *Model) checkDestination(to common.Address) error {
+	return p.addressList.check(to, p.tokens)
+}
+
+func (p *Model) ListAddressRulesModel() *AddressLists {
+	return p.addressList.lists()
+}
+
+func (p *Model) AddAddressRuleModel(list string, address string, note string) (*AddressRule, error) {
+	return p.addressList.add(list, address, note)
+}
+
+func (p *Model) RemoveAddressRuleModel(list string, address string) (*AddressRule, error) {
+	return p.addressList.remove(list, address)
+}
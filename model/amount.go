@@ -0,0 +1,74 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// 위믹스 코인의 소수점 자리수
+const coinDecimals = 18
+
+// 전송량 검증 실패시 반환되는 에러, controller에서 errors.Is로 구분
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// uint256 최대값, 컨트랙트에 전달 가능한 최대 전송량
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// 전송량 문자열을 최소 단위 값으로 변환
+// baseUnit이 true면 최소 단위 정수로, false면 "12.5" 같은 소수 문자열을 decimals 만큼 변환
+func ParseAmount(amount string, decimals uint8, baseUnit bool) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return nil, fmt.Errorf("%w: 전송량이 비어있습니다", ErrInvalidAmount)
+	}
+	if strings.HasPrefix(amount, "-") {
+		return nil, fmt.Errorf("%w: 음수는 전송할 수 없습니다", ErrInvalidAmount)
+	}
+
+	intPart, fracPart := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		if baseUnit {
+			return nil, fmt.Errorf("%w: 최소 단위 전송량은 정수여야 합니다", ErrInvalidAmount)
+		}
+		intPart, fracPart = amount[:i], amount[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return nil, fmt.Errorf("%w: %q 는 숫자가 아닙니다", ErrInvalidAmount, amount)
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return nil, fmt.Errorf("%w: %q 는 숫자가 아닙니다", ErrInvalidAmount, amount)
+	}
+
+	// 소수점 이하 자리수는 토큰의 decimals를 넘을 수 없음
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > int(decimals) {
+		return nil, fmt.Errorf("%w: 소수점 이하 자리수는 최대 %d자리입니다", ErrInvalidAmount, decimals)
+	}
+	if !baseUnit {
+		fracPart += strings.Repeat("0", int(decimals)-len(fracPart))
+	}
+
+	value, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q 는 숫자가 아닙니다", ErrInvalidAmount, amount)
+	}
+	if value.Sign() == 0 {
+		return nil, fmt.Errorf("%w: 전송량은 0보다 커야 합니다", ErrInvalidAmount)
+	}
+	if value.Cmp(maxUint256) > 0 {
+		return nil, fmt.Errorf("%w: 전송량이 uint256 범위를 초과합니다", ErrInvalidAmount)
+	}
+
+	return value, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		baseUnit bool
+		want     string
+	}{
+		{"12.5", 18, false, "12500000000000000000"},
+		{"1", 18, false, "1000000000000000000"},
+		{".5", 2, false, "50"},
+		{"0.700", 1, false, "7"},
+		{"700000000000000000", 18, true, "700000000000000000"},
+		{"3", 0, false, "3"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.amount, tt.decimals, tt.baseUnit)
+		if err != nil {
+			t.Errorf("ParseAmount(%q) Error: %s", tt.amount, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseAmount(%q) = %s, want %s", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmountInvalid(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		baseUnit bool
+	}{
+		{"", 18, false},
+		{"-1", 18, false},
+		{"0", 18, false},
+		{"0.0", 18, false},
+		{"1.123", 2, false},
+		{"1.5", 18, true},
+		{"abc", 18, false},
+		{"1e18", 18, false},
+		{".", 18, false},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639936", 0, true},
+		{"1000000000000000000000000000000000000000000000000000000000000000000000000", 18, false},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseAmount(tt.amount, tt.decimals, tt.baseUnit); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("ParseAmount(%q) Error = %v, want ErrInvalidAmount", tt.amount, err)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	conf "go-contract/config"
+)
+
+// API key scope, admin은 모든 scope를 포함
+const (
+	ScopeTokenRead = "token:read"
+	ScopeTokenSend = "token:send"
+	ScopeCoinRead  = "coin:read"
+	ScopeCoinSend  = "coin:send"
+	ScopeAdmin     = "admin"
+)
+
+var allScopes = []string{ScopeTokenRead, ScopeTokenSend, ScopeCoinRead, ScopeCoinSend, ScopeAdmin}
+
+var (
+	// API key가 없거나, 형식이 잘못되었거나, 폐기된 경우 반환
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// 없는 scope를 지정한 경우 반환
+	ErrInvalidScope = errors.New("invalid scope")
+	// 없는 API key id인 경우 반환
+	ErrUnknownAPIKey = errors.New("unknown api key")
+)
+
+// 발급한 API key, 원래 key는 저장하지 않고 secret의 SHA-256 hash만 보관
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Hash      string     `json:"hash,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// scope를 가지고 있으면 true, admin은 모든 scope 허용
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// 발급 결과, Key는 발급할 때만 응답에 포함
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// 이벤트 구독 ticket 유효 시간, 이 시간 안에 연결해야 하며 연결한 뒤에는 끊길 때까지 유지
+const streamTicketTTL = time.Minute
+
+// 이벤트 구독용 임시 ticket
+// 브라우저 EventSource, WebSocket은 헤더를 설정할 수 없으므로 API key 대신 쿼리로 전달
+type StreamTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type streamTicket struct {
+	keyID     string
+	expiresAt time.Time
+}
+
+// 발급한 API key 목록, path 파일에 기록하며 다른 프로세스(keygen)가 파일을 바꾸면 다시 읽음
+// 이벤트 구독 ticket은 메모리에만 보관
+type apiKeyStore struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	keys    []*APIKey
+	tickets map[string]streamTicket
+}
+
+func newAPIKeyStore(path string) (*apiKeyStore, error) {
+	s := &apiKeyStore{path: path, tickets: make(map[string]streamTicket)}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// 파일이 바뀌었으면 다시 읽음, 호출하는 쪽에서 mu를 잡고 있어야 함
+func (s *apiKeyStore) reload() error {
+	if s.path == "" {
+		return nil
+	}
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	var keys []*APIKey
+	if err := readJSON(s.path, &keys); err != nil {
+		return err
+	}
+	s.keys, s.modTime = keys, info.ModTime()
+	return nil
+}
+
+// 호출하는 쪽에서 mu를 잡고 있어야 함
+func (s *apiKeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := writeJSON(s.path, s.keys); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// 중복을 제거하고 정렬한 scope, 없는 scope가 있으면 ErrInvalidScope
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		valid := false
+		for _, s := range allScopes {
+			valid = valid || s == scope
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidScope)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// "id.secret" 형식의 key를 발급해 hash를 기록
+func (s *apiKeyStore) issue(name string, scopes []string) (*IssuedAPIKey, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	key := &APIKey{
+		ID:        id,
+		Name:      name,
+		Hash:      hashAPIKeySecret(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	s.keys = append(s.keys, key)
+	if err := s.save(); err != nil {
+		s.keys = s.keys[:len(s.keys)-1]
+		return nil, err
+	}
+	issued := &IssuedAPIKey{APIKey: *key, Key: id + "." + secret}
+	issued.Hash = ""
+	return issued, nil
+}
+
+// key의 hash를 비교해 발급한 key 반환
+func (s *apiKeyStore) authenticate(raw string) (*APIKey, error) {
+	parts := strings.SplitN(raw, ".", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidAPIKey
+	}
+	hash := hashAPIKeySecret(parts[1])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	for _, key := range s.keys {
+		if key.ID != parts[0] {
+			continue
+		}
+		if key.RevokedAt != nil || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) != 1 {
+			return nil, ErrInvalidAPIKey
+		}
+		k := *key
+		k.Hash = ""
+		return &k, nil
+	}
+	return nil, ErrInvalidAPIKey
+}
+
+// keyID의 이벤트 구독 ticket 발급, 만료된 ticket은 함께 정리
+func (s *apiKeyStore) issueTicket(keyID string) (*StreamTicket, error) {
+	ticket, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, v := range s.tickets {
+		if now.After(v.expiresAt) {
+			delete(s.tickets, t)
+		}
+	}
+	expiresAt := now.Add(streamTicketTTL)
+	s.tickets[ticket] = streamTicket{keyID: keyID, expiresAt: expiresAt}
+	return &StreamTicket{Ticket: ticket, ExpiresAt: expiresAt}, nil
+}
+
+// 만료되지 않은 ticket을 발급한 key 반환, 그 사이 key가 폐기되었으면 ErrInvalidAPIKey
+// EventSource가 같은 주소로 다시 연결할 수 있도록 유효 시간 안에는 여러 번 사용 가능
+func (s *apiKeyStore) authenticateTicket(ticket string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.tickets[ticket]
+	if !ok || time.Now().After(v.expiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	for _, key := range s.keys {
+		if key.ID != v.keyID || key.RevokedAt != nil {
+			continue
+		}
+		k := *key
+		k.Hash = ""
+		return &k, nil
+	}
+	return nil, ErrInvalidAPIKey
+}
+
+// hash를 제외한 key 목록, 폐기한 key도 포함
+func (s *apiKeyStore) list() ([]APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	keys := []APIKey{}
+	for _, key := range s.keys {
+		k := *key
+		k.Hash = ""
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyStore) revoke(id string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	for _, key := range s.keys {
+		if key.ID != id {
+			continue
+		}
+		if key.RevokedAt == nil {
+			now := time.Now().UTC()
+			key.RevokedAt = &now
+			if err := s.save(); err != nil {
+				key.RevokedAt = nil
+				return nil, err
+			}
+		}
+		k := *key
+		k.Hash = ""
+		return &k, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAPIKey, id)
+}
+
+// 서버를 띄우지 않고 config의 auth path 파일에 key 발급, keygen 명령에서 사용
+func IssueAPIKey(cfg *conf.Config, name string, scopes []string) (*IssuedAPIKey, error) {
+	if cfg.Auth.Path == "" {
+		return nil, errors.New("auth path is empty")
+	}
+	store, err := newAPIKeyStore(cfg.Auth.Path)
+	if err != nil {
+		return nil, err
+	}
+	return store.issue(name, scopes)
+}
+
+// API key 인증 사용 여부
+func (p *Model) AuthEnabled() bool {
+	return p.authEnabled
+}
+
+// 요청의 API key 확인
+func (p *Model) AuthenticateModel(raw string) (*APIKey, error) {
+	return p.apiKeys.authenticate(raw)
+}
+
+// 이벤트 구독 ticket 확인
+func (p *Model) AuthenticateStreamTicketModel(ticket string) (*APIKey, error) {
+	return p.apiKeys.authenticateTicket(ticket)
+}
+
+// 인증한 key id로 이벤트 구독 ticket 발급
+func (p *Model) IssueStreamTicketModel(keyID string) (*StreamTicket, error) {
+	return p.apiKeys.issueTicket(keyID)
+}
+
+func (p *Model) IssueAPIKeyModel(name string, scopes []string) (*IssuedAPIKey, error) {
+	return p.apiKeys.issue(name, scopes)
+}
+
+func (p *Model) ListAPIKeysModel() ([]APIKey, error) {
+	return p.apiKeys.list()
+}
+
+func (p *Model) RevokeAPIKeyModel(id string) (*APIKey, error) {
+	return p.apiKeys.revoke(id)
+}
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// block과 timestamp를 함께 지정한 경우 반환
+	ErrInvalidBlockAt = errors.New("block and timestamp are mutually exclusive")
+	// 아직 생성되지 않은 블록이나 시각, 또는 첫 블록 이전 시각을 지정한 경우 반환
+	ErrBlockNotFound = errors.New("block not found")
+)
+
+// 조회 시점, Block과 Timestamp(unix 초) 중 하나만 지정하며 둘 다 nil이면 최신 상태
+type BlockAt struct {
+	Block     *uint64
+	Timestamp *uint64
+}
+
+// 잔액 조회 결과, 시점을 지정하면 조회한 블록과 블록 시각을 채움
+type Balance struct {
+	Balance     *big.Int `json:"balance"`
+	BlockNumber *uint64  `json:"blockNumber,omitempty"`
+	Timestamp   *uint64  `json:"timestamp,omitempty"`
+}
+
+// 조회 시점의 블록 header, 시점을 지정하지 않았으면 nil
+// timestamp는 블록 시각이 timestamp 이하인 마지막 블록으로 정함
+func (p *Model) resolveBlockAt(ctx context.Context, client ChainBackend, at *BlockAt) (*types.Header, error) {
+	if at == nil || (at.Block == nil && at.Timestamp == nil) {
+		return nil, nil
+	}
+	if at.Block != nil && at.Timestamp != nil {
+		return nil, ErrInvalidBlockAt
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Error("HeaderByNumber 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	if at.Block != nil {
+		if *at.Block > head.Number.Uint64() {
+			return nil, fmt.Errorf("%w: block %d > latest %d", ErrBlockNotFound, *at.Block, head.Number.Uint64())
+		}
+		return p.headerByNumber(ctx, client, *at.Block)
+	}
+
+	ts := *at.Timestamp
+	if ts > head.Time {
+		return nil, fmt.Errorf("%w: timestamp %d is after latest block %d", ErrBlockNotFound, ts, head.Time)
+	}
+	// 블록 시각은 증가하므로 header를 이진 탐색
+	lo, hi := uint64(0), head.Number.Uint64()
+	found := head
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		header, err := p.headerByNumber(ctx, client, mid)
+		if err != nil {
+			return nil, err
+		}
+		if header.Time <= ts {
+			lo, found = mid, header
+		} else {
+			hi = mid - 1
+		}
+	}
+	if found.Number.Uint64() != lo {
+		if found, err = p.headerByNumber(ctx, client, lo); err != nil {
+			return nil, err
+		}
+	}
+	if found.Time > ts {
+		return nil, fmt.Errorf("%w: timestamp %d is before block %d", ErrBlockNotFound, ts, lo)
+	}
+	return found, nil
+}
+
+func (p *Model) headerByNumber(ctx context.Context, client ChainBackend, block uint64) (*types.Header, error) {
+	header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		log.Error("HeaderByNumber 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	return header, nil
+}
+
+// 조회한 블록을 결과에 채움
+func balanceAt(balance *big.Int, header *types.Header) *Balance {
+	result := &Balance{Balance: balance}
+	if header != nil {
+		block, ts := header.Number.Uint64(), header.Time
+		result.BlockNumber, result.Timestamp = &block, &ts
+	}
+	return result
+}
+
+// address의 토큰 잔액, at으로 과거 시점을 지정하면 해당 블록 상태로 조회하므로 archive 노드가 필요함
+func (p *Model) SearchTokenBalanceByAddressModel(token string, address string, at *BlockAt) (*Balance, error) {
+
+	// 토큰 컨트랙트 어드레스
+	instance, _, err := p.tokenCaller(token)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	header, err := p.resolveBlockAt(ctx, p.backend(), at)
+	if err != nil {
+		return nil, err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	if header != nil {
+		opts.BlockNumber = header.Number
+	}
+
+	targetAddress := common.HexToAddress(address)
+	balance, err := instance.BalanceOf(opts, targetAddress)
+	if err != nil {
+		log.Error("balance 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	return balanceAt(balance, header), nil
+}
+
+// address의 WEMIX 코인 잔액(wei), at은 토큰 잔액 조회와 같음
+func (p *Model) SearchCoinBalanceModel(address string, at *BlockAt) (*Balance, error) {
+	targetAddress, err := parseAddress("address", address)
+	if err != nil {
+		return nil, err
+	}
+	client := p.backend()
+	ctx := context.Background()
+	header, err := p.resolveBlockAt(ctx, client, at)
+	if err != nil {
+		return nil, err
+	}
+	var block *big.Int
+	if header != nil {
+		block = header.Number
+	}
+
+	balance, err := client.BalanceAt(ctx, targetAddress, block)
+	if err != nil {
+		log.Error("BalanceAt 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	return balanceAt(balance, header), nil
+}
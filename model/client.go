@@ -0,0 +1,133 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 모델이 사용하는 체인 접근 인터페이스
+// ethclient.Client와 테스트용 backends.SimulatedBackend 모두 이 인터페이스로 주입
+type ChainBackend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	NetworkID(ctx context.Context) (*big.Int, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// rpc 연결 확인시 사용할 제한 시간
+const dialTimeout = 10 * time.Second
+
+// rpc url로 클라이언트를 생성하고 ChainID 호출로 연결을 확인
+func dialClient(netUrl string) (*ethclient.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, netUrl)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := client.ChainID(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// 공유 체인 backend
+func (p *Model) backend() ChainBackend {
+	p.clientMu.RLock()
+	defer p.clientMu.RUnlock()
+	return p.client
+}
+
+// Close를 호출했는지 확인
+func (p *Model) isClosed() bool {
+	p.clientMu.RLock()
+	defer p.clientMu.RUnlock()
+	return p.closed
+}
+
+// rpc 호출 에러가 전송 계층 장애면 클라이언트를 다시 연결
+// 전송 요청은 중복 전송 위험이 있어 재시도하지 않고 다음 요청부터 새 클라이언트를 사용
+func (p *Model) checkConn(err error) {
+	// 외부에서 주입한 backend는 재연결하지 않음
+	if !p.dialed || !isTransportError(err) {
+		return
+	}
+
+	p.clientMu.Lock()
+	defer p.clientMu.Unlock()
+
+	if p.closed {
+		return
+	}
+	// 동시에 실패한 요청들이 연달아 재연결하지 않도록 제한
+	if time.Since(p.reconnectedAt) < time.Second {
+		return
+	}
+	p.reconnectedAt = time.Now()
+
+	client, dialErr := dialClient(p.netUrl)
+	if dialErr != nil {
+		log.Error("client 재연결 에러", dialErr.Error())
+		return
+	}
+	if old, ok := p.client.(*ethclient.Client); ok {
+		old.Close()
+	}
+	p.client = client
+	log.Info("client 재연결 완료", p.netUrl)
+}
+
+// 서버 종료시 rpc 클라이언트 정리, 외부에서 주입한 backend는 주입한 쪽에서 정리
+// 종료 중에 남은 요청이나 구독이 backend()를 호출할 수 있으므로 참조는 남겨두고 rpc.ErrClientQuit로 실패하게 함
+func (p *Model) Close() {
+	p.closeWatchBackend()
+	if p.indexer != nil {
+		p.indexer.store.close()
+	}
+
+	p.clientMu.Lock()
+	defer p.clientMu.Unlock()
+
+	p.closed = true
+	if client, ok := p.client.(*ethclient.Client); ok && p.dialed {
+		client.Close()
+	}
+}
+
+// 노드 응답 에러가 아닌 연결 자체의 장애인지 확인
+func isTransportError(err error) bool {
+	// context.DeadlineExceeded도 net.Error를 구현하므로 먼저 제외
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, rpc.ErrClientQuit) {
+		return true
+	}
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestCloseKeepsClient(t *testing.T) {
+	c, err := rpc.DialHTTP("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("DialHTTP Error: %s", err)
+	}
+	p := &Model{client: ethclient.NewClient(c), dialed: true, netUrl: "http://127.0.0.1:1", wsUrl: "ws://127.0.0.1:1"}
+	p.Close()
+
+	// 종료 후에 남은 요청은 panic 없이 에러를 받고, 재연결하지 않음
+	client := p.backend()
+	if client == nil {
+		t.Fatal("backend is nil after Close")
+	}
+	if _, err := client.NetworkID(context.Background()); err == nil {
+		t.Error("NetworkID after Close succeeded")
+	}
+	p.checkConn(rpc.ErrClientQuit)
+	if !p.reconnectedAt.IsZero() {
+		t.Error("reconnected after Close")
+	}
+	if _, err := p.watchBackend(); !errors.Is(err, rpc.ErrClientQuit) {
+		t.Errorf("watchBackend after Close err = %v, want ErrClientQuit", err)
+	}
+
+	// 종료 후에는 구독을 다시 시도하지 않고 out을 닫고 반환
+	stream := &EventStream{md: p, tracker: newEventTracker(nil)}
+	out := make(chan TokenEvent)
+	done := make(chan struct{})
+	go func() {
+		stream.Run(context.Background(), out)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("EventStream.Run did not return after Close")
+	}
+	if _, ok := <-out; ok {
+		t.Error("out is not closed")
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 배포 트랜잭션은 성공했지만 배포 주소에 bytecode가 없는 경우 반환
+var ErrNoContractCode = errors.New("no contract code at deployed address")
+
+// 배포 결과, 배포 기록과 레지스트리에 등록된 토큰 정보
+type DeployResult struct {
+	Deployment
+	Type     uint8      `json:"type"`
+	Nonce    uint64     `json:"nonce"`
+	GasLimit uint64     `json:"gasLimit"`
+	Token    *TokenInfo `json:"token,omitempty"`
+	Receipt  *TxStatus  `json:"receipt,omitempty"`
+}
+
+// config의 서비스 지갑으로 YKKToken을 배포
+// 영수증을 받을 때까지 대기한 뒤 배포 주소의 bytecode를 확인하고, statePath에 기록한 뒤 토큰 레지스트리에 등록
+func (p *Model) DeployTokenModel(wait *WaitOption) (*DeployResult, error) {
+	client := p.backend()
+	ctx := context.Background()
+
+	privateKey, err := p.signingKey()
+	if err != nil {
+		return nil, err
+	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		log.Error("NetworkID 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	// 생성된 바인딩의 DeployContracts로 배포 트랜잭션 생성 및 전송
+	signedTx, _, err := p.transactContract(ctx, client, privateKey, chainID, nil, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		_, tx, _, err := cont.DeployContracts(opts, client)
+		return tx, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 배포 주소는 배포 계정과 nonce로 결정됨
+	contractAddress := crypto.CreateAddress(fromAddress, signedTx.Nonce())
+	result := &DeployResult{
+		Deployment: Deployment{
+			Address:  contractAddress.Hex(),
+			TxHash:   signedTx.Hash().Hex(),
+			Deployer: fromAddress.Hex(),
+			ChainID:  bigString(chainID),
+		},
+		Type:     signedTx.Type(),
+		Nonce:    signedTx.Nonce(),
+		GasLimit: signedTx.Gas(),
+	}
+	log.Info("컨트랙트 배포 전송", result.TxHash+" "+result.Address)
+
+	if wait == nil {
+		wait = &WaitOption{}
+	}
+	receipt, err := p.waitReceipt(client, signedTx, wait)
+	result.Receipt = receipt
+	if err != nil {
+		log.Error("영수증 대기 에러", err.Error())
+		return result, err
+	}
+	result.BlockNumber = receipt.BlockNumber
+	result.DeployedAt = time.Now().UTC()
+
+	// 배포 주소에 bytecode가 있는지 확인
+	code, err := client.CodeAt(ctx, contractAddress, nil)
+	if err != nil {
+		log.Error("CodeAt 에러", err.Error())
+		p.checkConn(err)
+		return result, err
+	}
+	if len(code) == 0 {
+		return result, fmt.Errorf("%w: %s", ErrNoContractCode, result.Address)
+	}
+
+	if err := p.recordDeployment(result.Deployment); err != nil {
+		log.Error("배포 기록 에러", err.Error())
+		return result, err
+	}
+
+	info, err := p.fetchTokenInfo(ctx, result.Address)
+	if err != nil {
+		return result, err
+	}
+	// 기본 토큰이 없으면 배포한 토큰을 기본 토큰으로 사용
+	if !p.tokens.hasDefault() {
+		info.Default = true
+	}
+	if err := p.tokens.add(info); err != nil {
+		return result, err
+	}
+	result.Token = info
+	log.Info("컨트랙트 배포 완료", result.Address)
+	return result, nil
+}
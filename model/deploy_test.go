@@ -0,0 +1,74 @@
+package model
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 서버가 같은 config로 실행 중이어도 색인 저장소 lock과 충돌하지 않고 배포
+func TestDeployModelWithRunningServer(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	funds, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	sim := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: funds},
+	}, 30000000)}
+	defer sim.Close()
+
+	cfg := new(conf.Config)
+	cfg.Contract.PrivateKey = hexutil.Encode(crypto.FromECDSA(key))[2:]
+	cfg.Contract.StatePath = filepath.Join(t.TempDir(), "state.json")
+	cfg.Indexer.Enabled = true
+	cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+	cfg.Log.Fpath = filepath.Join(t.TempDir(), "test")
+	cfg.Log.Msize = 1
+	if err := log.InitLogger(cfg); err != nil {
+		t.Fatalf("InitLogger Error: %s", err)
+	}
+	server, err := NewModel(cfg, sim)
+	if err != nil {
+		t.Fatalf("NewModel Error: %s", err)
+	}
+	defer server.Close()
+
+	p, err := NewDeployModel(cfg, sim)
+	if err != nil {
+		t.Fatalf("NewDeployModel Error: %s", err)
+	}
+	defer p.Close()
+	if p.indexer != nil || p.webhooks != nil || p.policy != nil {
+		t.Error("deploy model opened server state")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(50 * time.Millisecond):
+				sim.Commit()
+			}
+		}
+	}()
+	result, err := p.DeployTokenModel(nil)
+	close(done)
+	if err != nil {
+		t.Fatalf("DeployTokenModel Error: %s", err)
+	}
+	caller, _ := cont.NewContractsCaller(crypto.CreateAddress(crypto.PubkeyToAddress(key.PublicKey), 0), sim)
+	if symbol, err := caller.Symbol(&bind.CallOpts{}); err != nil || symbol != "YKK" || result.Nonce != 0 {
+		t.Errorf("deployed = %+v, symbol %q, %v", result, symbol, err)
+	}
+}
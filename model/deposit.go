@@ -0,0 +1,321 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 입금 종류
+const (
+	DepositToken = "token"
+	DepositCoin  = "coin"
+)
+
+const (
+	// 한 번에 확인하는 블록 수, 코인 입금은 블록마다 조회
+	maxDepositScanBlocks = 100
+	// 전송 실패시 다시 보내기 전 대기 시간
+	minDeliveryDelay = time.Second
+	maxDeliveryDelay = 5 * time.Minute
+)
+
+// webhook으로 알리는 입금 한 건
+// token은 Transfer 로그, coin은 값이 있는 트랜잭션으로 LogIndex가 없음
+type Deposit struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Token       string `json:"token,omitempty"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+	TxHash      string `json:"txHash"`
+	BlockNumber uint64 `json:"blockNumber"`
+	LogIndex    *uint  `json:"logIndex,omitempty"`
+}
+
+// callback에 보내는 JSON body
+type webhookPayload struct {
+	WebhookID string `json:"webhookId"`
+	Deposit
+}
+
+// pollInterval마다 confirmations 블록이 쌓인 새 블록을 확인해 입금 알림을 outbox에 기록하고 전송
+// ctx가 끝나면 진행 중인 전송이 멈출 때까지 기다린 뒤 반환, 보내지 못한 알림은 outbox에 남아 재시작 후 전송
+func (p *Model) RunWebhooks(ctx context.Context) {
+	m := p.webhooks
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := p.scanDeposits(ctx); err != nil && ctx.Err() == nil {
+			log.Error("입금 확인 에러", err.Error())
+		}
+		p.dispatchDeliveries(ctx)
+		select {
+		case <-ctx.Done():
+			m.deliveries.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 마지막으로 확인한 블록 다음부터 confirmations 블록이 쌓인 블록까지 입금을 찾아 outbox에 기록
+func (p *Model) scanDeposits(ctx context.Context) error {
+	m := p.webhooks
+	client := p.backend()
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		p.checkConn(err)
+		return err
+	}
+	// 입금 블록도 확인 수에 포함
+	if head.Number.Uint64()+1 < m.confirmations {
+		return nil
+	}
+	safe := head.Number.Uint64() + 1 - m.confirmations
+
+	last := m.lastBlock()
+	watched := m.watched()
+	if last == nil || len(watched) == 0 {
+		// 처음 실행하거나 등록된 webhook이 없으면 지난 블록은 확인하지 않음
+		// 재시작 후 나중에 등록한 webhook으로 지난 입금을 보내지 않도록 확인한 블록을 저장
+		if last == nil || *last < safe {
+			return m.setLastBlock(safe)
+		}
+		return nil
+	}
+
+	for from := *last + 1; from <= safe; {
+		to := from + maxDepositScanBlocks - 1
+		if to > safe {
+			to = safe
+		}
+		deposits, err := p.findDeposits(ctx, client, watched, from, to)
+		if err != nil {
+			return err
+		}
+		var deliveries []*outboxDelivery
+		for _, dep := range deposits {
+			for _, hook := range watched[common.HexToAddress(dep.To)] {
+				deliveries = append(deliveries, &outboxDelivery{ID: hook.ID + ":" + dep.ID, WebhookID: hook.ID, Deposit: dep})
+			}
+		}
+		// 알림을 outbox에 기록한 뒤에 마지막으로 확인한 블록을 갱신
+		if err := m.enqueue(deliveries, to); err != nil {
+			return err
+		}
+		from = to + 1
+	}
+	return nil
+}
+
+// 블록 구간에서 watched 주소로 들어온 토큰, 코인 입금을 블록 순서로 조회
+// 토큰은 레지스트리의 모든 토큰에 대해 FilterTransfer로, 코인은 블록의 트랜잭션 중 성공한 값 전송으로 확인
+// 컨트랙트 내부에서 보낸 코인은 트랜잭션에 나타나지 않아 확인하지 않음
+func (p *Model) findDeposits(ctx context.Context, client ChainBackend, watched map[common.Address][]Webhook, from uint64, to uint64) ([]Deposit, error) {
+	addresses := make([]common.Address, 0, len(watched))
+	for addr := range watched {
+		addresses = append(addresses, addr)
+	}
+
+	deposits := []Deposit{}
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+	for _, token := range p.tokens.list() {
+		filterer, err := cont.NewContractsFilterer(common.HexToAddress(token.Address), client)
+		if err != nil {
+			return nil, err
+		}
+		it, err := filterer.FilterTransfer(opts, nil, addresses)
+		if err != nil {
+			p.checkConn(err)
+			return nil, err
+		}
+		for it.Next() {
+			raw := it.Event.Raw
+			index := raw.Index
+			deposits = append(deposits, Deposit{
+				ID:          logPosition{block: raw.BlockNumber, index: raw.Index}.String(),
+				Type:        DepositToken,
+				Token:       raw.Address.Hex(),
+				From:        it.Event.From.Hex(),
+				To:          it.Event.To.Hex(),
+				Value:       bigString(it.Event.Value),
+				TxHash:      raw.TxHash.Hex(),
+				BlockNumber: raw.BlockNumber,
+				LogIndex:    &index,
+			})
+		}
+		err = it.Error()
+		it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	for n := from; n <= to; n++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			p.checkConn(err)
+			return nil, err
+		}
+		for _, tx := range block.Transactions() {
+			if tx.To() == nil || tx.Value().Sign() == 0 || watched[*tx.To()] == nil {
+				continue
+			}
+			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+			if err != nil {
+				return nil, err
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				continue
+			}
+			sender, err := types.Sender(signer, tx)
+			if err != nil {
+				return nil, err
+			}
+			deposits = append(deposits, Deposit{
+				ID:          tx.Hash().Hex(),
+				Type:        DepositCoin,
+				From:        sender.Hex(),
+				To:          tx.To().Hex(),
+				Value:       bigString(tx.Value()),
+				TxHash:      tx.Hash().Hex(),
+				BlockNumber: n,
+			})
+		}
+	}
+
+	sort.SliceStable(deposits, func(i, j int) bool { return deposits[i].BlockNumber < deposits[j].BlockNumber })
+	return deposits, nil
+}
+
+// outbox의 알림을 동시에 maxDeliveryWorkers개까지 전송, 나머지는 앞의 전송이 끝나면 이어서 전송
+func (p *Model) dispatchDeliveries(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	m := p.webhooks
+	for _, d := range m.claim() {
+		m.deliveries.Add(1)
+		go p.deliverWebhook(ctx, d)
+	}
+}
+
+// 입금 알림 전송, 실패하면 maxAttempts까지 간격을 늘려가며 다시 보내고 끝내 실패하면 dead letter로 기록
+// ctx가 끝나면 더 기다리지 않고 outbox에 남김
+func (p *Model) deliverWebhook(ctx context.Context, d outboxDelivery) {
+	m := p.webhooks
+	defer m.deliveries.Done()
+	// 끝난 자리만큼 다음 알림 전송, Done 전에 호출해 종료 대기 중에도 WaitGroup이 0이 되지 않음
+	defer p.dispatchDeliveries(ctx)
+
+	hook, ok := m.webhook(d.WebhookID)
+	if !ok {
+		// 전송 전에 삭제한 webhook
+		if err := m.complete(d.ID, nil); err != nil {
+			log.Error("webhook outbox 저장 에러", err.Error())
+		}
+		return
+	}
+	dep := d.Deposit
+
+	var err error
+	attempts := 0
+	delay := minDeliveryDelay
+retry:
+	for attempts < m.maxAttempts {
+		attempts++
+		if err = p.postWebhook(ctx, hook, dep); err == nil {
+			log.Info("webhook 전송 완료", hook.ID+" "+dep.ID)
+			if err := m.complete(d.ID, nil); err != nil {
+				log.Error("webhook outbox 저장 에러", err.Error())
+			}
+			return
+		}
+		log.Error("webhook 전송 에러", hook.ID+" "+dep.ID+" "+err.Error())
+		if attempts == m.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break retry
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxDeliveryDelay {
+			delay = maxDeliveryDelay
+		}
+	}
+
+	if ctx.Err() != nil {
+		m.unclaim(d.ID)
+		return
+	}
+	id, idErr := randomHex(8)
+	if idErr != nil {
+		log.Error("dead letter id 생성 에러", idErr.Error())
+		m.unclaim(d.ID)
+		return
+	}
+	if err := m.complete(d.ID, &DeadLetter{
+		ID:        id,
+		WebhookID: hook.ID,
+		URL:       hook.URL,
+		Deposit:   dep,
+		Attempts:  attempts,
+		LastError: err.Error(),
+		FailedAt:  time.Now().UTC(),
+	}); err != nil {
+		log.Error("dead letter 기록 에러", err.Error())
+	}
+}
+
+// 서명한 입금 알림을 한 번 전송, 2xx 응답이 아니면 에러
+func (p *Model) postWebhook(ctx context.Context, hook Webhook, dep Deposit) error {
+	body, err := json.Marshal(webhookPayload{WebhookID: hook.ID, Deposit: dep})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.webhooks.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, signWebhook(hook.Secret, timestamp, body))
+	req.Header.Set(WebhookDeliveryHeader, hook.ID+":"+dep.ID)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("callback status %d", res.StatusCode)
+	}
+	return nil
+}
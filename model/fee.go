@@ -0,0 +1,120 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxFeeMultiplier 설정이 없을 때 사용하는 기본값
+const defaultMaxFeeMultiplier = 2.0
+
+// baseFee가 maxFeePerGas 상한보다 높아 전송할 수 없는 경우 반환
+var ErrFeeCapTooLow = errors.New("max fee per gas cap is below base fee")
+
+// 트랜잭션 수수료 설정, gasFeeCap이 있으면 dynamic fee 트랜잭션
+type txFee struct {
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+	gasFeeCap *big.Int
+}
+
+func (f *txFee) dynamic() bool {
+	return f.gasFeeCap != nil
+}
+
+// 수수료 방식에 맞춰 서명 전 트랜잭션 생성, to가 nil이면 컨트랙트 배포 트랜잭션
+func (f *txFee) newTx(chainID *big.Int, nonce uint64, to *common.Address, value *big.Int, gasLimit uint64, data []byte) *types.Transaction {
+	if f.dynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: f.gasTipCap,
+			GasFeeCap: f.gasFeeCap,
+			Gas:       gasLimit,
+			To:        to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: f.gasPrice,
+		Gas:      gasLimit,
+		To:       to,
+		Value:    value,
+		Data:     data,
+	})
+}
+
+// 전송 결과에 수수료 정보 기록
+func (f *txFee) fill(result *SendResult) {
+	if f.dynamic() {
+		result.MaxFeePerGas = bigString(f.gasFeeCap)
+		result.MaxPriorityFeePerGas = bigString(f.gasTipCap)
+		return
+	}
+	result.GasPrice = bigString(f.gasPrice)
+}
+
+// 현재 네트워크 상태로 수수료 계산
+// dynamic fee 설정시 최신 블록의 baseFee와 추천 tip으로 계산하고, baseFee가 없는 체인은 legacy로 대체
+func (p *Model) suggestFee(ctx context.Context, client ChainBackend) (*txFee, error) {
+	if p.dynamicFee {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			log.Error("HeaderByNumber 에러", err.Error())
+			p.checkConn(err)
+			return nil, err
+		}
+		if head.BaseFee != nil {
+			return p.dynamicFeeFor(ctx, client, head.BaseFee)
+		}
+	}
+
+	// gasPrice 설정. 추천되는 gasPrice를 가져옴
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		log.Error("SuggestGasPrice 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	return &txFee{gasPrice: gasPrice}, nil
+}
+
+// maxFeePerGas = baseFee * multiplier + tip, 설정된 상한을 넘지 않도록 조정
+func (p *Model) dynamicFeeFor(ctx context.Context, client ChainBackend, baseFee *big.Int) (*txFee, error) {
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		log.Error("SuggestGasTipCap 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	if p.maxPriorityFeePerGas != nil && tip.Cmp(p.maxPriorityFeePerGas) > 0 {
+		tip = new(big.Int).Set(p.maxPriorityFeePerGas)
+	}
+
+	// multiplier는 소수점 둘째 자리까지 반영
+	multiplier := big.NewInt(int64(p.maxFeeMultiplier * 100))
+	feeCap := new(big.Int).Mul(baseFee, multiplier)
+	feeCap.Div(feeCap, big.NewInt(100))
+	feeCap.Add(feeCap, tip)
+
+	if p.maxFeePerGas != nil && feeCap.Cmp(p.maxFeePerGas) > 0 {
+		if p.maxFeePerGas.Cmp(baseFee) < 0 {
+			return nil, fmt.Errorf("%w: baseFee %s, maxFeePerGas %s", ErrFeeCapTooLow, baseFee, p.maxFeePerGas)
+		}
+		feeCap = new(big.Int).Set(p.maxFeePerGas)
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+
+	return &txFee{gasTipCap: tip, gasFeeCap: feeCap}, nil
+}
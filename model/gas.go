@@ -0,0 +1,110 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// gasMargin 설정이 없을 때 사용하는 예상 가스 여유분(%)
+const defaultGasMargin = 20
+
+var (
+	// 가스 예측 단계에서 트랜잭션이 revert 될 것으로 확인된 경우 반환
+	ErrExecutionReverted = errors.New("execution reverted")
+	// 예상 가스가 maxGasLimit 설정을 넘는 경우 반환
+	ErrGasLimitExceeded = errors.New("estimated gas exceeds max gas limit")
+)
+
+// solidity Panic(uint256) 셀렉터
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// solidity Panic 코드별 설명
+var panicReasons = map[uint64]string{
+	0x01: "assert 실패",
+	0x11: "산술 연산 overflow 또는 underflow",
+	0x12: "0으로 나누기",
+	0x21: "잘못된 enum 값",
+	0x22: "잘못된 storage byte array 접근",
+	0x31: "빈 배열 pop",
+	0x32: "배열 범위 초과 접근",
+	0x41: "메모리 할당 초과",
+	0x51: "초기화되지 않은 함수 호출",
+}
+
+// 실제 호출 메시지로 가스를 예측하고 여유분을 더해 gasLimit 결정
+// to가 nil인 컨트랙트 배포는 호출보다 가스가 훨씬 크므로 maxGasLimit을 적용하지 않음
+// revert가 예상되면 가능한 경우 revert 사유를 디코딩해 ErrExecutionReverted로 반환
+func (p *Model) estimateGas(ctx context.Context, client ChainBackend, from common.Address, to *common.Address, value *big.Int, data []byte) (uint64, error) {
+	estimated, err := client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    to,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		log.Error("EstimateGas 에러", err.Error())
+		p.checkConn(err)
+		if reason, ok := revertReason(err); ok {
+			return 0, fmt.Errorf("%w: %s", ErrExecutionReverted, reason)
+		}
+		return 0, err
+	}
+
+	maxGasLimit := p.maxGasLimit
+	if to == nil {
+		maxGasLimit = 0
+	}
+	if maxGasLimit > 0 && estimated > maxGasLimit {
+		return 0, fmt.Errorf("%w: 예상 %d, 최대 %d", ErrGasLimitExceeded, estimated, maxGasLimit)
+	}
+
+	gasLimit := estimated * (100 + p.gasMargin) / 100
+	if maxGasLimit > 0 && gasLimit > maxGasLimit {
+		gasLimit = maxGasLimit
+	}
+	return gasLimit, nil
+}
+
+// 가스 예측 에러에서 revert 여부와 사유 확인
+// 노드가 revert 데이터를 돌려주면 Error(string), Panic(uint256)을 디코딩
+func revertReason(err error) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil && len(data) > 0 {
+				return decodeRevert(data), true
+			}
+		}
+	}
+	if strings.Contains(err.Error(), "execution reverted") {
+		return "사유 없음", true
+	}
+	return "", false
+}
+
+// revert 데이터 디코딩, 알 수 없는 형식은 hex 그대로 반환
+func decodeRevert(data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) == 36 && bytes.Equal(data[:4], panicSelector) {
+		code := new(big.Int).SetBytes(data[4:]).Uint64()
+		if reason, ok := panicReasons[code]; ok {
+			return fmt.Sprintf("panic 0x%02x: %s", code, reason)
+		}
+		return fmt.Sprintf("panic 0x%02x", code)
+	}
+	return hexutil.Encode(data)
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestDecodeRevert(t *testing.T) {
+	// Error(string) 형식의 revert 데이터
+	typ, _ := abi.NewType("string", "", nil)
+	packed, err := abi.Arguments{{Type: typ}}.Pack("insufficient balance")
+	if err != nil {
+		t.Fatalf("Pack Error: %s", err)
+	}
+	errorData := append(hexutil.MustDecode("0x08c379a0"), packed...)
+
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{errorData, "insufficient balance"},
+		{hexutil.MustDecode("0x4e487b710000000000000000000000000000000000000000000000000000000000000011"), "panic 0x11: 산술 연산 overflow 또는 underflow"},
+		{hexutil.MustDecode("0x12345678"), "0x12345678"},
+	}
+
+	for _, tt := range tests {
+		if got := decodeRevert(tt.data); got != tt.want {
+			t.Errorf("decodeRevert(%x) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// logRange 설정이 없을 때 FilterTransfer 한 번에 조회하는 블록 수
+	defaultLogRange = 5000
+	// 한 요청에서 조회하는 최대 구간 수, 넘으면 cursor로 이어서 조회
+	maxScanRanges = 20
+
+	defaultTransferLimit = 100
+	maxTransferLimit     = 1000
+)
+
+// 전송 방향, 비어있으면 보내고 받은 전송 모두
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+var (
+	// 블록 구간이 잘못된 경우 반환
+	ErrInvalidBlockRange = errors.New("invalid block range")
+	// direction 값이 잘못된 경우 반환
+	ErrInvalidDirection = errors.New("invalid direction")
+	// cursor 형식이 잘못된 경우 반환
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// 전송 내역 조회 조건
+// FromBlock, ToBlock이 nil이면 0번 블록부터 최신 블록까지, Cursor가 있으면 FromBlock 대신 cursor 위치부터 조회
+// 색인한 토큰은 FromBlock이 nil이면 토큰을 색인하기 시작한 블록부터 색인한 블록까지
+type TransferQuery struct {
+	Token     string
+	Address   string
+	Direction string
+	FromBlock *uint64
+	ToBlock   *uint64
+	Limit     int
+	Cursor    string
+}
+
+// 전송 내역 한 건
+type TransferRecord struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Value       string `json:"value"`
+}
+
+// 전송 내역 페이지, Next가 있으면 cursor로 넘겨 다음 페이지 조회
+type TransferPage struct {
+	Token     string           `json:"token"`
+	Address   string           `json:"address"`
+	Direction string           `json:"direction,omitempty"`
+	FromBlock uint64           `json:"fromBlock"`
+	ToBlock   uint64           `json:"toBlock"`
+	Transfers []TransferRecord `json:"transfers"`
+	Next      string           `json:"next,omitempty"`
+}
+
+// 블록 번호와 로그 index로 정한 조회 위치, 문자열로는 "블록:index"
+type logPosition struct {
+	block uint64
+	index uint
+}
+
+func (l logPosition) String() string {
+	return fmt.Sprintf("%d:%d", l.block, l.index)
+}
+
+func (l logPosition) before(block uint64, index uint) bool {
+	return block < l.block || (block == l.block && index < l.index)
+}
+
+func parseCursor(cursor string) (logPosition, error) {
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return logPosition{}, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+	block, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return logPosition{}, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return logPosition{}, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+	return logPosition{block: block, index: uint(index)}, nil
+}
+
+// address의 토큰 전송 내역을 FilterTransfer로 조회
+// rpc 노드의 로그 조회 구간 제한을 넘지 않도록 logRange 블록씩 나눠 조회하며, limit을 채우거나 maxScanRanges 구간을 넘으면 Next를 채워 반환
+func (p *Model) SearchTransfersModel(q *TransferQuery) (*TransferPage, error) {
+	address, err := parseAddress("address", q.Address)
+	if err != nil {
+		return nil, err
+	}
+	if q.Direction != "" && q.Direction != DirectionIn && q.Direction != DirectionOut {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDirection, q.Direction)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultTransferLimit
+	} else if limit > maxTransferLimit {
+		limit = maxTransferLimit
+	}
+
+	token, err := p.resolveToken(q.Token)
+	if err != nil {
+		return nil, err
+	}
+	client := p.backend()
+	ctx := context.Background()
+	tokenAddress := common.HexToAddress(token.Address)
+	filterer, err := cont.NewContractsFilterer(tokenAddress, client)
+	if err != nil {
+		log.Error("NewContractsFilterer 에러", err.Error())
+		return nil, err
+	}
+
+	// 조회 구간 결정, to_block이 없으면 최신 블록까지
+	var fromBlock, toBlock uint64
+	if q.FromBlock != nil {
+		fromBlock = *q.FromBlock
+	}
+	// 구간을 색인했으면 로컬에서 조회, to_block이 없으면 색인한 블록까지
+	local := false
+	if p.indexer != nil {
+		if indexedStart, head, ok := p.indexer.indexedRange(tokenAddress); ok {
+			from := fromBlock
+			if q.FromBlock == nil {
+				from = indexedStart
+			}
+			if from >= indexedStart && from <= head && (q.ToBlock == nil || *q.ToBlock <= head) {
+				local, fromBlock, toBlock = true, from, head
+			}
+		}
+	}
+	if q.ToBlock != nil {
+		toBlock = *q.ToBlock
+	} else if !local {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			log.Error("HeaderByNumber 에러", err.Error())
+			p.checkConn(err)
+			return nil, err
+		}
+		toBlock = head.Number.Uint64()
+	}
+	if fromBlock > toBlock {
+		return nil, fmt.Errorf("%w: from_block %d > to_block %d", ErrInvalidBlockRange, fromBlock, toBlock)
+	}
+	start := logPosition{block: fromBlock}
+	if q.Cursor != "" {
+		if start, err = parseCursor(q.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	page := &TransferPage{
+		Token:     token.Address,
+		Address:   address.Hex(),
+		Direction: q.Direction,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+		Transfers: []TransferRecord{},
+	}
+	if local {
+		if err := p.indexer.searchTransfers(page, tokenAddress, address, q.Direction, start, toBlock, limit); err != nil {
+			log.Error("색인 조회 에러", err.Error())
+			return nil, err
+		}
+		return page, nil
+	}
+	logRange := p.logRange
+	for scanned := 0; start.block <= toBlock; scanned++ {
+		if scanned == maxScanRanges {
+			page.Next = start.String()
+			return page, nil
+		}
+		end := start.block + logRange - 1
+		if end > toBlock || end < start.block {
+			end = toBlock
+		}
+
+		records, err := p.filterTransfers(ctx, filterer, address, q.Direction, start.block, end)
+		if isLogRangeError(err) && end > start.block {
+			// 노드가 구간 또는 결과 수 제한으로 거부하면 구간을 줄여 다시 조회
+			logRange = (end - start.block + 1) / 2
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, r := range records {
+			if start.before(r.BlockNumber, r.LogIndex) {
+				continue
+			}
+			if len(page.Transfers) == limit {
+				page.Next = logPosition{block: r.BlockNumber, index: r.LogIndex}.String()
+				return page, nil
+			}
+			page.Transfers = append(page.Transfers, r)
+		}
+		if end == toBlock {
+			break
+		}
+		start = logPosition{block: end + 1}
+	}
+	return page, nil
+}
+
+// 한 구간의 전송 내역을 블록, 로그 순서로 조회
+// direction이 비어있으면 보낸 전송과 받은 전송을 합치고 자기 자신에게 보낸 전송은 한 번만 포함
+func (p *Model) filterTransfers(ctx context.Context, filterer *cont.ContractsFilterer, address common.Address, direction string, start uint64, end uint64) ([]TransferRecord, error) {
+	opts := &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+	type rule struct{ from, to []common.Address }
+	var rules []rule
+	if direction != DirectionIn {
+		rules = append(rules, rule{from: []common.Address{address}})
+	}
+	if direction != DirectionOut {
+		rules = append(rules, rule{to: []common.Address{address}})
+	}
+
+	seen := make(map[logPosition]bool)
+	records := []TransferRecord{}
+	for _, r := range rules {
+		it, err := filterer.FilterTransfer(opts, r.from, r.to)
+		if err != nil {
+			log.Error("FilterTransfer 에러", err.Error())
+			p.checkConn(err)
+			return nil, err
+		}
+		for it.Next() {
+			raw := it.Event.Raw
+			pos := logPosition{block: raw.BlockNumber, index: raw.Index}
+			if seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			records = append(records, TransferRecord{
+				BlockNumber: raw.BlockNumber,
+				TxHash:      raw.TxHash.Hex(),
+				LogIndex:    raw.Index,
+				From:        it.Event.From.Hex(),
+				To:          it.Event.To.Hex(),
+				Value:       bigString(it.Event.Value),
+			})
+		}
+		err = it.Error()
+		it.Close()
+		if err != nil {
+			log.Error("Transfer 로그 조회 에러", err.Error())
+			return nil, err
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].BlockNumber != records[j].BlockNumber {
+			return records[i].BlockNumber < records[j].BlockNumber
+		}
+		return records[i].LogIndex < records[j].LogIndex
+	})
+	return records, nil
+}
+
+// 노드의 로그 조회 구간, 결과 수 제한 에러
+func isLogRangeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"block range", "more than", "too many", "query timeout", "limit exceeded"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
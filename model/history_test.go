@@ -0,0 +1,27 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCursor(t *testing.T) {
+	pos, err := parseCursor("120:3")
+	if err != nil || pos != (logPosition{block: 120, index: 3}) || pos.String() != "120:3" {
+		t.Errorf("parseCursor = %v, %v", pos, err)
+	}
+	for _, cursor := range []string{"", "120", "a:1", "1:b", "1:2:3"} {
+		if _, err := parseCursor(cursor); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("parseCursor(%q) Error = %v", cursor, err)
+		}
+	}
+}
+
+func TestIsLogRangeError(t *testing.T) {
+	if !isLogRangeError(errors.New("query returned more than 10000 results")) || !isLogRangeError(errors.New("exceed maximum block range: 5000")) {
+		t.Error("log range errors not detected")
+	}
+	if isLogRangeError(errors.New("connection refused")) || isLogRangeError(nil) {
+		t.Error("unexpected log range error")
+	}
+}
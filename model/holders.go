@@ -0,0 +1,310 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultHolderLimit = 100
+	maxHolderLimit     = 1000
+	defaultTopHolders  = 10
+	// 보유량 확인을 위해 동시에 보내는 BalanceOf 호출 수
+	holderBalanceWorkers = 8
+)
+
+// 보유자 정렬 순서, 비어있으면 보유량이 많은 순
+const (
+	SortDesc = "desc"
+	SortAsc  = "asc"
+)
+
+var (
+	// indexer를 사용하지 않거나 토큰을 색인하지 않은 경우 반환
+	ErrTokenNotIndexed = errors.New("token is not indexed")
+	// sort 값이 잘못된 경우 반환
+	ErrInvalidSort = errors.New("invalid sort")
+)
+
+// 보유자 조회 조건, Top은 보유 비율을 합산할 상위 보유자 수
+type HolderQuery struct {
+	Token  string
+	Sort   string
+	Offset int
+	Limit  int
+	Top    int
+}
+
+// 보유자 한 명, Share는 TotalSupply 대비 보유 비율(%)
+// 색인한 전송으로 계산한 보유량이 BalanceOf와 다르면 IndexedBalance에 계산한 값을 채움
+type Holder struct {
+	Address        string `json:"address"`
+	Balance        string `json:"balance"`
+	Share          string `json:"share"`
+	IndexedBalance string `json:"indexedBalance,omitempty"`
+}
+
+// 보유자 페이지, Offset부터 Limit명
+type HolderPage struct {
+	Token       string   `json:"token"`
+	Block       uint64   `json:"block"`
+	TotalSupply string   `json:"totalSupply"`
+	HolderCount int      `json:"holderCount"`
+	Top         int      `json:"top"`
+	TopBalance  string   `json:"topBalance"`
+	TopShare    string   `json:"topShare"`
+	Sort        string   `json:"sort"`
+	Offset      int      `json:"offset"`
+	Limit       int      `json:"limit"`
+	Holders     []Holder `json:"holders"`
+}
+
+type holderBalance struct {
+	address common.Address
+	balance *big.Int
+	indexed *big.Int
+}
+
+// 색인한 Transfer 이벤트로 계산한 보유량 순으로 조회, 응답할 페이지와 상위 보유자만 BalanceOf로 확인
+// YKKToken 생성자의 초기 발행량은 Transfer 이벤트가 없으므로 statePath에 기록된 배포 계정과 서비스 지갑도 후보에 포함
+func (p *Model) SearchTokenHoldersModel(q *HolderQuery) (*HolderPage, error) {
+	if q.Sort == "" {
+		q.Sort = SortDesc
+	} else if q.Sort != SortDesc && q.Sort != SortAsc {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSort, q.Sort)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultHolderLimit
+	} else if limit > maxHolderLimit {
+		limit = maxHolderLimit
+	}
+	offset := q.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	top := q.Top
+	if top <= 0 {
+		top = defaultTopHolders
+	}
+
+	instance, token, err := p.tokenCaller(q.Token)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddress := common.HexToAddress(token.Address)
+	if p.indexer == nil {
+		return nil, fmt.Errorf("%w: indexer disabled", ErrTokenNotIndexed)
+	}
+	_, head, ok := p.indexer.indexedRange(tokenAddress)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTokenNotIndexed, token.Address)
+	}
+
+	// 색인한 전송으로 주소별 보유량 계산
+	indexed := make(map[common.Address]*big.Int)
+	add := func(address string, v *big.Int) {
+		addr := common.HexToAddress(address)
+		if addr == (common.Address{}) {
+			return
+		}
+		if indexed[addr] == nil {
+			indexed[addr] = new(big.Int)
+		}
+		indexed[addr].Add(indexed[addr], v)
+	}
+	err = p.indexer.store.eachEvent(tokenAddress, nil, logPosition{}, head, func(ev TokenEvent) bool {
+		if ev.Event != EventTransfer {
+			return true
+		}
+		v, _ := new(big.Int).SetString(ev.Value, 10)
+		add(ev.From, new(big.Int).Neg(v))
+		add(ev.To, v)
+		return true
+	})
+	if err != nil {
+		log.Error("색인 조회 에러", err.Error())
+		return nil, err
+	}
+	// Transfer 이벤트 없이 보유량을 가질 수 있는 후보는 색인 값 대신 BalanceOf로 정렬
+	verified := make(map[common.Address]*big.Int)
+	for _, addr := range p.holderCandidates(token.Address) {
+		add(addr.Hex(), new(big.Int))
+		verified[addr] = nil
+	}
+	// 보유량은 색인한 head 블록 기준으로 확인
+	opts := &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(head)}
+	if err := p.verifyHolderBalances(instance, opts, verified); err != nil {
+		return nil, err
+	}
+
+	totalSupply, err := instance.TotalSupply(opts)
+	if err != nil {
+		log.Error("TotalSupply 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	// 색인으로 계산한 보유량 순으로 정렬, 보낸 적만 있고 잔액이 0인 주소는 제외
+	holders := make([]*holderBalance, 0, len(indexed))
+	for addr, v := range indexed {
+		h := &holderBalance{address: addr, indexed: v, balance: v}
+		if balance, ok := verified[addr]; ok {
+			h.balance = balance
+		}
+		if h.balance.Sign() > 0 {
+			holders = append(holders, h)
+		}
+	}
+	sortHolders(holders, q.Sort)
+	end := offset + limit
+	if end > len(holders) || end < offset {
+		end = len(holders)
+	}
+	start := offset
+	if start > end {
+		start = end
+	}
+	selected := holders[start:end]
+
+	// 응답할 페이지와 상위 보유자만 BalanceOf로 확인
+	var tops []*holderBalance
+	for i := 0; i < top && i < len(holders); i++ {
+		if q.Sort == SortAsc {
+			tops = append(tops, holders[len(holders)-1-i])
+		} else {
+			tops = append(tops, holders[i])
+		}
+	}
+	for _, h := range append(append([]*holderBalance{}, selected...), tops...) {
+		if _, ok := verified[h.address]; !ok {
+			verified[h.address] = nil
+		}
+	}
+	if err := p.verifyHolderBalances(instance, opts, verified); err != nil {
+		return nil, err
+	}
+	for _, h := range holders {
+		if balance, ok := verified[h.address]; ok {
+			h.balance = balance
+		}
+	}
+	// 확인한 보유량으로 페이지 안의 순서를 다시 맞춤
+	sortHolders(selected, q.Sort)
+	topBalance := new(big.Int)
+	for _, h := range tops {
+		topBalance.Add(topBalance, h.balance)
+	}
+
+	page := &HolderPage{
+		Token:       token.Address,
+		Block:       head,
+		TotalSupply: bigString(totalSupply),
+		HolderCount: len(holders),
+		Top:         top,
+		TopBalance:  bigString(topBalance),
+		TopShare:    shareOf(topBalance, totalSupply),
+		Sort:        q.Sort,
+		Offset:      offset,
+		Limit:       limit,
+		Holders:     []Holder{},
+	}
+	for _, h := range selected {
+		holder := Holder{
+			Address: h.address.Hex(),
+			Balance: bigString(h.balance),
+			Share:   shareOf(h.balance, totalSupply),
+		}
+		if h.indexed.Cmp(h.balance) != 0 {
+			holder.IndexedBalance = h.indexed.String()
+		}
+		page.Holders = append(page.Holders, holder)
+	}
+	return page, nil
+}
+
+// 보유량이 많은 순, 같으면 주소 순, order가 SortAsc면 그 반대 순서
+func sortHolders(holders []*holderBalance, order string) {
+	sort.Slice(holders, func(i, j int) bool {
+		c := holders[i].balance.Cmp(holders[j].balance)
+		if c == 0 {
+			c = strings.Compare(strings.ToLower(holders[j].address.Hex()), strings.ToLower(holders[i].address.Hex()))
+		}
+		if order == SortAsc {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
+// balances에서 값이 nil인 주소를 opts 블록 기준 BalanceOf로 채움
+func (p *Model) verifyHolderBalances(instance *cont.ContractsCaller, opts *bind.CallOpts, balances map[common.Address]*big.Int) error {
+	var pending []common.Address
+	for addr, balance := range balances {
+		if balance == nil {
+			pending = append(pending, addr)
+		}
+	}
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(holderBalanceWorkers)
+	for _, addr := range pending {
+		addr := addr
+		g.Go(func() error {
+			balance, err := instance.BalanceOf(opts, addr)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			balances[addr] = balance
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		log.Error("balance 조회 에러", err.Error())
+		p.checkConn(err)
+		return err
+	}
+	return nil
+}
+
+// Transfer 이벤트 없이 보유량을 가질 수 있는 주소
+// statePath에 기록된 token의 배포 계정과 서비스 지갑
+func (p *Model) holderCandidates(token string) []common.Address {
+	var addrs []common.Address
+	if p.statePath != "" {
+		if state, err := readState(p.statePath); err == nil {
+			for _, d := range state.Deployments {
+				if strings.EqualFold(d.Address, token) && common.IsHexAddress(d.Deployer) {
+					addrs = append(addrs, common.HexToAddress(d.Deployer))
+				}
+			}
+		}
+	}
+	if key, err := crypto.HexToECDSA(p.privateKey); err == nil {
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return addrs
+}
+
+// total 대비 v의 비율(%), 소수점 4자리
+func shareOf(v *big.Int, total *big.Int) string {
+	if total.Sign() == 0 {
+		return "0"
+	}
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(v, big.NewInt(100)), total)
+	return r.FloatString(4)
+}
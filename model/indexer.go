@@ -0,0 +1,426 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	conf "go-contract/config"
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// indexer 설정이 없을 때 새 블록 확인 간격
+	defaultIndexerPollInterval = 5 * time.Second
+	// reorg 확인을 위해 hash를 보관하는 최근 블록 수, 이보다 깊은 reorg는 확인하지 않음
+	reorgWindow = 128
+)
+
+// 색인 중 체인이 바뀌어 이번 색인을 중단한 경우, 다음 확인에서 되돌린 뒤 다시 색인
+var errIndexReorg = errors.New("chain reorganized while indexing")
+
+// 레지스트리 토큰의 Transfer, Approval 로그를 새 블록마다 로컬 저장소에 색인
+// 저장한 블록 hash가 체인과 달라지면 달라진 블록부터 되돌린 뒤 다시 색인
+type indexer struct {
+	store        *indexStore
+	startBlock   uint64
+	pollInterval time.Duration
+	parser       *cont.ContractsFilterer
+
+	// 색인과 되돌리기가 동시에 실행되지 않도록 잠금
+	mu sync.Mutex
+}
+
+// 색인 상태
+type IndexerStatus struct {
+	Enabled bool           `json:"enabled"`
+	Head    *uint64        `json:"head,omitempty"`
+	Tokens  []IndexedToken `json:"tokens"`
+}
+
+// 색인 중인 토큰, StartBlock부터 색인한 블록까지 로컬에서 조회
+type IndexedToken struct {
+	Address    string `json:"address"`
+	StartBlock uint64 `json:"startBlock"`
+}
+
+// config의 indexer 설정으로 저장소를 열고, 사용하지 않으면 nil 반환
+func newIndexer(cfg *conf.Config) (*indexer, error) {
+	if !cfg.Indexer.Enabled {
+		return nil, nil
+	}
+	if cfg.Indexer.Path == "" {
+		return nil, errors.New("indexer path is empty")
+	}
+	parser, err := cont.NewContractsFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	store, err := openIndexStore(cfg.Indexer.Path)
+	if err != nil {
+		return nil, err
+	}
+	ix := &indexer{store: store, startBlock: cfg.Indexer.StartBlock, parser: parser}
+	ix.pollInterval = time.Duration(cfg.Indexer.PollInterval) * time.Second
+	if ix.pollInterval <= 0 {
+		ix.pollInterval = defaultIndexerPollInterval
+	}
+	return ix, nil
+}
+
+// token을 from 블록부터 색인했으면 색인한 마지막 블록 반환
+func (ix *indexer) coveredHead(token common.Address, from uint64) (uint64, bool) {
+	start, ok, err := ix.store.tokenStart(token)
+	if err != nil || !ok || start > from {
+		return 0, false
+	}
+	head, ok, err := ix.store.head()
+	if err != nil || !ok || head < from {
+		return 0, false
+	}
+	return head, true
+}
+
+// token을 색인한 범위, token을 색인하기 시작한 블록부터 마지막으로 색인한 블록까지
+// config의 startBlock을 토큰 배포 블록으로 설정하면 시작 블록이 0이 아니므로 조회 기본 범위로 사용
+func (ix *indexer) indexedRange(token common.Address) (uint64, uint64, bool) {
+	start, ok, err := ix.store.tokenStart(token)
+	if err != nil || !ok {
+		return 0, 0, false
+	}
+	head, ok := ix.coveredHead(token, start)
+	return start, head, ok
+}
+
+// pollInterval마다 새 블록을 색인, indexer를 사용하지 않으면 바로 반환
+func (p *Model) RunIndexer(ctx context.Context) {
+	if p.indexer == nil {
+		return
+	}
+	ticker := time.NewTicker(p.indexer.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := p.indexOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Error("색인 에러", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 마지막으로 색인한 블록의 reorg를 확인해 되돌린 뒤 최신 블록까지 색인
+func (p *Model) indexOnce(ctx context.Context) error {
+	ix := p.indexer
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	client := p.backend()
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		p.checkConn(err)
+		return err
+	}
+	head := header.Number.Uint64()
+
+	next := ix.startBlock
+	last, ok, err := ix.store.head()
+	if err != nil {
+		return err
+	}
+	if ok {
+		if next, err = p.indexResumeBlock(ctx, client, last, head); err != nil {
+			return err
+		}
+		if next <= last {
+			if err := ix.store.rollback(next); err != nil {
+				return err
+			}
+			log.Info("reorg로 색인 되돌림", fmt.Sprintf("%d -> %d", last, next-1))
+		}
+	}
+	if next > head {
+		return nil
+	}
+
+	tokens, err := p.syncIndexTokens(ctx, client, next, ok)
+	if err != nil {
+		return err
+	}
+
+	logRange := p.logRange
+	for from := next; from <= head; {
+		to := from + logRange - 1
+		if to > head || to < from {
+			to = head
+		}
+		var logs []types.Log
+		if len(tokens) > 0 {
+			logs, err = client.FilterLogs(ctx, tokenLogQuery(tokens, from, to))
+			if isLogRangeError(err) && to > from {
+				logRange = (to - from + 1) / 2
+				continue
+			} else if err != nil {
+				p.checkConn(err)
+				return err
+			}
+		}
+
+		batch := new(leveldb.Batch)
+		// reorg 확인용으로 최근 reorgWindow 블록의 hash 저장
+		hashes := make(map[uint64]common.Hash)
+		low := from
+		if head >= reorgWindow && head-reorgWindow+1 > low {
+			low = head - reorgWindow + 1
+		}
+		for n := low; n <= to; n++ {
+			h, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+			if err != nil {
+				return err
+			}
+			hashes[n] = h.Hash()
+			batch.Put(joinKey(prefixHash, encodeBlock(n)), h.Hash().Bytes())
+		}
+		for _, l := range logs {
+			// 로그 조회와 header 조회 사이에 체인이 바뀐 경우
+			if h, ok := hashes[l.BlockNumber]; ok && h != l.BlockHash {
+				return errIndexReorg
+			}
+			ev, err := ix.parse(l)
+			if err != nil {
+				return err
+			}
+			if err := putEvent(batch, ev); err != nil {
+				return err
+			}
+		}
+		if to >= reorgWindow {
+			if err := ix.store.pruneHashes(batch, to-reorgWindow+1); err != nil {
+				return err
+			}
+		}
+		batch.Put(keyHead, encodeBlock(to))
+		if err := ix.store.db.Write(batch, nil); err != nil {
+			return err
+		}
+		from = to + 1
+	}
+	return nil
+}
+
+// 저장한 hash가 체인과 같은 마지막 블록의 다음 블록, reorg가 없으면 last+1
+// hash를 보관하지 않는 오래된 블록은 확정된 것으로 봄
+func (p *Model) indexResumeBlock(ctx context.Context, client ChainBackend, last uint64, head uint64) (uint64, error) {
+	ix := p.indexer
+	for n := last; ; n-- {
+		stored, ok, err := ix.store.blockHash(n)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return n + 1, nil
+		}
+		if n <= head {
+			header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+			if err != nil {
+				return 0, err
+			}
+			if header.Hash() == stored {
+				return n + 1, nil
+			}
+		}
+		if n == 0 || n <= ix.startBlock {
+			return n, nil
+		}
+	}
+}
+
+// Transfer, Approval 로그 조회 조건
+func tokenLogQuery(tokens []common.Address, from uint64, to uint64) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: tokens,
+		Topics:    [][]common.Hash{{tokenEventID("Transfer"), tokenEventID("Approval")}},
+	}
+}
+
+// 레지스트리의 토큰을 색인 대상으로 맞춤
+// 처음 색인하면 startBlock부터 색인하고, 이미 색인 중에 새로 등록된 토큰(backfill)은 배포 블록부터 next 이전 블록까지 먼저 색인
+// 토큰의 시작 블록은 backfill을 마친 뒤에 기록하므로 그 전에는 보유자, 전송 내역을 색인에서 조회하지 않음
+// 레지스트리에서 제거된 토큰은 색인 대상에서 빼고 색인한 이벤트도 지움
+func (p *Model) syncIndexTokens(ctx context.Context, client ChainBackend, next uint64, backfill bool) ([]common.Address, error) {
+	ix := p.indexer
+	indexed, err := ix.store.tokens()
+	if err != nil {
+		return nil, err
+	}
+	batch := new(leveldb.Batch)
+	registered := make(map[common.Address]bool)
+	var tokens []common.Address
+	for _, info := range p.tokens.list() {
+		token := common.HexToAddress(info.Address)
+		registered[token] = true
+		tokens = append(tokens, token)
+		if _, ok := indexed[token]; ok {
+			continue
+		}
+		start := next
+		if !backfill {
+			start = ix.startBlock
+		} else if next > 0 {
+			start = p.tokenCreationBlock(ctx, client, token, next-1)
+			if err := p.backfillToken(ctx, client, token, start, next-1); err != nil {
+				return nil, err
+			}
+			log.Info("토큰 색인 backfill 완료", fmt.Sprintf("%s %d -> %d", token.Hex(), start, next-1))
+		}
+		batch.Put(joinKey(prefixToken, token.Bytes()), encodeBlock(start))
+	}
+	for token := range indexed {
+		if registered[token] {
+			continue
+		}
+		if err := ix.store.deleteToken(batch, token); err != nil {
+			return nil, err
+		}
+		log.Info("토큰 색인 삭제", token.Hex())
+	}
+	return tokens, ix.store.db.Write(batch, nil)
+}
+
+// token 컨트랙트가 배포된 블록을 head 이하에서 찾음, 과거 상태를 조회할 수 없는 노드면 0
+func (p *Model) tokenCreationBlock(ctx context.Context, client ChainBackend, token common.Address, head uint64) uint64 {
+	lo, hi := uint64(0), head
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		code, err := client.CodeAt(ctx, token, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0
+		}
+		if len(code) > 0 {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+// 색인 중에 새로 등록된 token의 from부터 to 블록까지 이벤트 색인, head와 블록 hash는 바꾸지 않음
+func (p *Model) backfillToken(ctx context.Context, client ChainBackend, token common.Address, from uint64, to uint64) error {
+	ix := p.indexer
+	logRange := p.logRange
+	for from <= to {
+		end := from + logRange - 1
+		if end > to || end < from {
+			end = to
+		}
+		logs, err := client.FilterLogs(ctx, tokenLogQuery([]common.Address{token}, from, end))
+		if isLogRangeError(err) && end > from {
+			logRange = (end - from + 1) / 2
+			continue
+		} else if err != nil {
+			p.checkConn(err)
+			return err
+		}
+		batch := new(leveldb.Batch)
+		for _, l := range logs {
+			ev, err := ix.parse(l)
+			if err != nil {
+				return err
+			}
+			if err := putEvent(batch, ev); err != nil {
+				return err
+			}
+		}
+		if err := ix.store.db.Write(batch, nil); err != nil {
+			return err
+		}
+		if end == to {
+			break
+		}
+		from = end + 1
+	}
+	return nil
+}
+
+func (ix *indexer) parse(l types.Log) (TokenEvent, error) {
+	switch l.Topics[0] {
+	case tokenEventID("Transfer"):
+		ev, err := ix.parser.ParseTransfer(l)
+		if err != nil {
+			return TokenEvent{}, err
+		}
+		return transferEvent(ev), nil
+	case tokenEventID("Approval"):
+		ev, err := ix.parser.ParseApproval(l)
+		if err != nil {
+			return TokenEvent{}, err
+		}
+		return approvalEvent(ev), nil
+	}
+	return TokenEvent{}, fmt.Errorf("unknown event %s", l.Topics[0].Hex())
+}
+
+// 색인한 블록과 토큰
+func (p *Model) IndexerStatusModel() (*IndexerStatus, error) {
+	status := &IndexerStatus{Tokens: []IndexedToken{}}
+	if p.indexer == nil {
+		return status, nil
+	}
+	status.Enabled = true
+	head, ok, err := p.indexer.store.head()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		status.Head = &head
+	}
+	tokens, err := p.indexer.store.tokens()
+	if err != nil {
+		return nil, err
+	}
+	for token, start := range tokens {
+		status.Tokens = append(status.Tokens, IndexedToken{Address: token.Hex(), StartBlock: start})
+	}
+	sort.Slice(status.Tokens, func(i, j int) bool { return status.Tokens[i].Address < status.Tokens[j].Address })
+	return status, nil
+}
+
+// 색인에서 address의 전송 내역 조회, 결과 형식은 FilterTransfer로 조회한 경우와 같음
+func (ix *indexer) searchTransfers(page *TransferPage, token common.Address, address common.Address, direction string, start logPosition, toBlock uint64, limit int) error {
+	return ix.store.eachEvent(token, &address, start, toBlock, func(ev TokenEvent) bool {
+		if ev.Event != EventTransfer {
+			return true
+		}
+		if (direction == DirectionIn && ev.To != address.Hex()) || (direction == DirectionOut && ev.From != address.Hex()) {
+			return true
+		}
+		if len(page.Transfers) == limit {
+			page.Next = ev.ID()
+			return false
+		}
+		page.Transfers = append(page.Transfers, TransferRecord{
+			BlockNumber: ev.BlockNumber,
+			TxHash:      ev.TxHash,
+			LogIndex:    ev.LogIndex,
+			From:        ev.From,
+			To:          ev.To,
+			Value:       ev.Value,
+		})
+		return true
+	})
+}
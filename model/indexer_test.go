@@ -0,0 +1,210 @@
+package model
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	conf "go-contract/config"
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SimulatedBackend에는 NetworkID가 없어 체인 설정의 ChainID로 대신함
+type simBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (b *simBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	return b.Blockchain().Config().ChainID, nil
+}
+
+func TestIndexerReorg(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	funds, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	sim := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: funds},
+	}, 30000000)}
+	defer sim.Close()
+	auth, _ := bind.NewKeyedTransactorWithChainID(key, sim.Blockchain().Config().ChainID)
+	token, _, contract, err := cont.DeployContracts(auth, sim)
+	if err != nil {
+		t.Fatalf("DeployContracts Error: %s", err)
+	}
+	sim.Commit()
+
+	cfg := new(conf.Config)
+	cfg.Contract.TokenAddress = token.Hex()
+	cfg.Indexer.Enabled = true
+	cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+	cfg.Log.Fpath = filepath.Join(t.TempDir(), "test")
+	cfg.Log.Msize = 1
+	if err := log.InitLogger(cfg); err != nil {
+		t.Fatalf("InitLogger Error: %s", err)
+	}
+	p, err := NewModel(cfg, sim)
+	if err != nil {
+		t.Fatalf("NewModel Error: %s", err)
+	}
+	defer p.Close()
+
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	transfer := func(amount int64) {
+		t.Helper()
+		if _, err := contract.Transfer(auth, to, big.NewInt(amount)); err != nil {
+			t.Fatalf("Transfer Error: %s", err)
+		}
+		sim.Commit()
+	}
+	count := func(address common.Address) int {
+		t.Helper()
+		n := 0
+		if err := p.indexer.store.eachEvent(token, &address, logPosition{}, ^uint64(0), func(TokenEvent) bool {
+			n++
+			return true
+		}); err != nil {
+			t.Fatalf("eachEvent Error: %s", err)
+		}
+		return n
+	}
+
+	transfer(1)
+	parent := sim.Blockchain().CurrentBlock().Hash()
+	transfer(2)
+	if err := p.indexOnce(ctx); err != nil {
+		t.Fatalf("indexOnce Error: %s", err)
+	}
+	if n := count(to); n != 2 {
+		t.Errorf("indexed transfers = %d, want 2", n)
+	}
+	page, err := p.SearchTransfersModel(&TransferQuery{Address: to.Hex()})
+	if err != nil || len(page.Transfers) != 2 || page.ToBlock != 3 || page.Transfers[1].Value != "2" {
+		t.Fatalf("SearchTransfersModel = %+v, %v", page, err)
+	}
+
+	// 두 번째 전송이 없는 더 긴 체인으로 reorg
+	if err := sim.Fork(ctx, parent); err != nil {
+		t.Fatalf("Fork Error: %s", err)
+	}
+	if _, err := contract.Approve(auth, to, big.NewInt(5)); err != nil {
+		t.Fatalf("Approve Error: %s", err)
+	}
+	sim.Commit()
+	sim.Commit()
+	if err := p.indexOnce(ctx); err != nil {
+		t.Fatalf("indexOnce after reorg Error: %s", err)
+	}
+	head, _, _ := p.indexer.store.head()
+	if n := count(to); n != 2 || head != 4 {
+		t.Errorf("after reorg events = %d, head = %d, want 2, 4", n, head)
+	}
+	page, err = p.SearchTransfersModel(&TransferQuery{Address: to.Hex()})
+	if err != nil || len(page.Transfers) != 1 || page.Transfers[0].Value != "1" {
+		t.Errorf("SearchTransfersModel after reorg = %+v, %v", page, err)
+	}
+	hash, ok, _ := p.indexer.store.blockHash(3)
+	if !ok || hash != sim.Blockchain().GetBlockByNumber(3).Hash() {
+		t.Errorf("block hash not updated after reorg")
+	}
+}
+
+// 레지스트리에서 제거한 토큰은 이벤트를 지우고, 다시 등록하면 배포 블록부터 다시 색인
+func TestIndexerRemoveToken(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	funds, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	sim := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: funds},
+	}, 30000000)}
+	defer sim.Close()
+	auth, _ := bind.NewKeyedTransactorWithChainID(key, sim.Blockchain().Config().ChainID)
+	token, _, _, err := cont.DeployContracts(auth, sim)
+	if err != nil {
+		t.Fatalf("DeployContracts Error: %s", err)
+	}
+	second, _, contract, err := cont.DeployContracts(auth, sim)
+	if err != nil {
+		t.Fatalf("DeployContracts Error: %s", err)
+	}
+	sim.Commit()
+
+	cfg := new(conf.Config)
+	cfg.Contract.TokenAddress = token.Hex()
+	cfg.Indexer.Enabled = true
+	cfg.Indexer.Path = filepath.Join(t.TempDir(), "index")
+	cfg.Log.Fpath = filepath.Join(t.TempDir(), "test")
+	cfg.Log.Msize = 1
+	if err := log.InitLogger(cfg); err != nil {
+		t.Fatalf("InitLogger Error: %s", err)
+	}
+	p, err := NewModel(cfg, sim)
+	if err != nil {
+		t.Fatalf("NewModel Error: %s", err)
+	}
+	defer p.Close()
+
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	count := func() int {
+		t.Helper()
+		n := 0
+		for _, address := range []*common.Address{nil, &to} {
+			if err := p.indexer.store.eachEvent(second, address, logPosition{}, ^uint64(0), func(TokenEvent) bool {
+				n++
+				return true
+			}); err != nil {
+				t.Fatalf("eachEvent Error: %s", err)
+			}
+		}
+		return n
+	}
+	register := func() {
+		t.Helper()
+		info, err := p.fetchTokenInfo(ctx, second.Hex())
+		if err != nil {
+			t.Fatalf("fetchTokenInfo Error: %s", err)
+		}
+		if err := p.tokens.add(info); err != nil {
+			t.Fatalf("add Error: %s", err)
+		}
+	}
+
+	register()
+	if _, err := contract.Transfer(auth, to, big.NewInt(1)); err != nil {
+		t.Fatalf("Transfer Error: %s", err)
+	}
+	sim.Commit()
+	if err := p.indexOnce(ctx); err != nil {
+		t.Fatalf("indexOnce Error: %s", err)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("indexed rows = %d, want 2", n)
+	}
+
+	if _, err := p.tokens.remove(second.Hex()); err != nil {
+		t.Fatalf("remove Error: %s", err)
+	}
+	sim.Commit()
+	if err := p.indexOnce(ctx); err != nil {
+		t.Fatalf("indexOnce Error: %s", err)
+	}
+	if _, ok, _ := p.indexer.store.tokenStart(second); ok || count() != 0 {
+		t.Errorf("removed token rows = %d, start %v", count(), ok)
+	}
+
+	register()
+	sim.Commit()
+	if err := p.indexOnce(ctx); err != nil {
+		t.Fatalf("indexOnce Error: %s", err)
+	}
+	if n := count(); n != 2 {
+		t.Errorf("re-added token rows = %d, want 2", n)
+	}
+}
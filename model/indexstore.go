@@ -0,0 +1,244 @@
+package model
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// 색인 저장소 key 구성
+//
+//	H                                     마지막으로 색인한 블록
+//	h + block                             색인한 블록의 hash, reorg 확인용으로 최근 reorgWindow 블록만 보관
+//	t + token                             토큰의 색인 시작 블록
+//	l + token + block + index             이벤트
+//	a + token + address + block + index   주소별 이벤트 위치, from, to, owner, spender 모두 기록
+var (
+	keyHead      = []byte("H")
+	prefixHash   = []byte("h")
+	prefixToken  = []byte("t")
+	prefixLog    = []byte("l")
+	prefixByAddr = []byte("a")
+)
+
+// leveldb에 토큰 이벤트와 블록 hash를 저장
+type indexStore struct {
+	db *leveldb.DB
+}
+
+func openIndexStore(path string) (*indexStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &indexStore{db: db}, nil
+}
+
+func (s *indexStore) close() error {
+	return s.db.Close()
+}
+
+func encodeBlock(block uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, block)
+	return b
+}
+
+func positionKey(block uint64, index uint) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint64(b, block)
+	binary.BigEndian.PutUint32(b[8:], uint32(index))
+	return b
+}
+
+func joinKey(parts ...[]byte) []byte {
+	var key []byte
+	for _, p := range parts {
+		key = append(key, p...)
+	}
+	return key
+}
+
+// 키가 없으면 ok가 false
+func (s *indexStore) getBlock(key []byte) (uint64, bool, error) {
+	v, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return binary.BigEndian.Uint64(v), true, nil
+}
+
+func (s *indexStore) head() (uint64, bool, error) {
+	return s.getBlock(keyHead)
+}
+
+func (s *indexStore) blockHash(block uint64) (common.Hash, bool, error) {
+	v, err := s.db.Get(joinKey(prefixHash, encodeBlock(block)), nil)
+	if err == leveldb.ErrNotFound {
+		return common.Hash{}, false, nil
+	} else if err != nil {
+		return common.Hash{}, false, err
+	}
+	return common.BytesToHash(v), true, nil
+}
+
+func (s *indexStore) tokenStart(token common.Address) (uint64, bool, error) {
+	return s.getBlock(joinKey(prefixToken, token.Bytes()))
+}
+
+// 색인 중인 토큰과 시작 블록
+func (s *indexStore) tokens() (map[common.Address]uint64, error) {
+	tokens := make(map[common.Address]uint64)
+	it := s.db.NewIterator(util.BytesPrefix(prefixToken), nil)
+	defer it.Release()
+	for it.Next() {
+		tokens[common.BytesToAddress(it.Key()[len(prefixToken):])] = binary.BigEndian.Uint64(it.Value())
+	}
+	return tokens, it.Error()
+}
+
+// 이벤트와 주소별 위치를 batch에 추가
+func putEvent(batch *leveldb.Batch, ev TokenEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	token := common.HexToAddress(ev.Token).Bytes()
+	pos := positionKey(ev.BlockNumber, ev.LogIndex)
+	batch.Put(joinKey(prefixLog, token, pos), data)
+	for _, addr := range eventAddresses(ev) {
+		batch.Put(joinKey(prefixByAddr, token, addr.Bytes(), pos), nil)
+	}
+	return nil
+}
+
+// 이벤트와 관련된 주소, 자기 자신에게 보낸 전송은 한 번만 포함
+func eventAddresses(ev TokenEvent) []common.Address {
+	var addrs []common.Address
+	for _, a := range []string{ev.From, ev.To, ev.Owner, ev.Spender} {
+		if a == "" {
+			continue
+		}
+		addr := common.HexToAddress(a)
+		dup := false
+		for _, b := range addrs {
+			dup = dup || b == addr
+		}
+		if !dup {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// from 블록부터의 이벤트와 블록 hash를 지우고 head를 from 이전 블록으로 되돌림
+func (s *indexStore) rollback(from uint64) error {
+	tokens, err := s.tokens()
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	for token := range tokens {
+		prefix := joinKey(prefixLog, token.Bytes())
+		it := s.db.NewIterator(&util.Range{Start: joinKey(prefix, positionKey(from, 0)), Limit: util.BytesPrefix(prefix).Limit}, nil)
+		for it.Next() {
+			var ev TokenEvent
+			if err := json.Unmarshal(it.Value(), &ev); err != nil {
+				it.Release()
+				return err
+			}
+			batch.Delete(append([]byte{}, it.Key()...))
+			pos := positionKey(ev.BlockNumber, ev.LogIndex)
+			for _, addr := range eventAddresses(ev) {
+				batch.Delete(joinKey(prefixByAddr, token.Bytes(), addr.Bytes(), pos))
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	if err := s.deleteRange(batch, &util.Range{Start: joinKey(prefixHash, encodeBlock(from)), Limit: util.BytesPrefix(prefixHash).Limit}); err != nil {
+		return err
+	}
+	if from == 0 {
+		batch.Delete(keyHead)
+	} else {
+		batch.Put(keyHead, encodeBlock(from-1))
+	}
+	return s.db.Write(batch, nil)
+}
+
+// below 블록 이전의 hash를 지우도록 batch에 추가
+func (s *indexStore) pruneHashes(batch *leveldb.Batch, below uint64) error {
+	return s.deleteRange(batch, &util.Range{Start: prefixHash, Limit: joinKey(prefixHash, encodeBlock(below))})
+}
+
+// 색인 대상에서 뺀 token의 시작 블록과 이벤트를 모두 지우도록 batch에 추가
+// rollback은 등록된 토큰만 되돌리므로 남겨두면 다시 등록했을 때 reorg로 취소된 이벤트가 섞일 수 있음
+func (s *indexStore) deleteToken(batch *leveldb.Batch, token common.Address) error {
+	batch.Delete(joinKey(prefixToken, token.Bytes()))
+	for _, prefix := range [][]byte{joinKey(prefixLog, token.Bytes()), joinKey(prefixByAddr, token.Bytes())} {
+		if err := s.deleteRange(batch, util.BytesPrefix(prefix)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rng의 key를 모두 지우도록 batch에 추가
+func (s *indexStore) deleteRange(batch *leveldb.Batch, rng *util.Range) error {
+	it := s.db.NewIterator(rng, nil)
+	defer it.Release()
+	for it.Next() {
+		batch.Delete(append([]byte{}, it.Key()...))
+	}
+	return it.Error()
+}
+
+// start 위치부터 toBlock까지 token의 이벤트를 순서대로 fn에 전달, address가 있으면 관련 이벤트만
+// fn이 false를 반환하면 중단
+func (s *indexStore) eachEvent(token common.Address, address *common.Address, start logPosition, toBlock uint64, fn func(TokenEvent) bool) error {
+	prefix := joinKey(prefixLog, token.Bytes())
+	if address != nil {
+		prefix = joinKey(prefixByAddr, token.Bytes(), address.Bytes())
+	}
+	rng := &util.Range{Start: joinKey(prefix, positionKey(start.block, start.index))}
+	if toBlock == ^uint64(0) {
+		rng.Limit = util.BytesPrefix(prefix).Limit
+	} else {
+		rng.Limit = joinKey(prefix, positionKey(toBlock+1, 0))
+	}
+
+	// 색인 중에도 같은 시점의 데이터를 읽도록 snapshot 사용
+	snap, err := s.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+	it := snap.NewIterator(rng, nil)
+	defer it.Release()
+	for it.Next() {
+		data := it.Value()
+		if address != nil {
+			v, err := snap.Get(joinKey(prefixLog, token.Bytes(), it.Key()[len(prefix):]), nil)
+			if err != nil {
+				return err
+			}
+			data = v
+		}
+		var ev TokenEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return err
+		}
+		if !fn(ev) {
+			break
+		}
+	}
+	return it.Error()
+}
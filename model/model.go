@@ -2,23 +2,18 @@ package model
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"encoding/hex"
 	"errors"
-	"fmt"
 	conf "go-contract/config"
 	cont "go-contract/contracts"
 	log "go-contract/logger"
 	"math/big"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rlp"
-	"golang.org/x/crypto/sha3"
 )
 
 type Model struct {
@@ -27,258 +22,379 @@ type Model struct {
 	transactionHash    string
 	tokenAddress       string
 	constructorAddress string
-}
+	waitConfirmations  uint64
+	waitTimeout        time.Duration
 
-func NewModel(cfg *conf.Config) (*Model, error) {
-	r := &Model{}
-	r.privateKey = cfg.Contract.PrivateKey
-	r.netUrl = cfg.Contract.NetUrl
-	r.transactionHash = cfg.Contract.TransactionHash
-	r.tokenAddress = cfg.Contract.TokenAddress
-	r.constructorAddress = cfg.Contract.ConstructorAddress
+	// EIP-1559 dynamic fee 설정, 상한이 nil이면 제한 없음
+	dynamicFee           bool
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+	maxFeeMultiplier     float64
 
-	return r, nil
-}
+	// gasLimit 예측 설정
+	gasMargin   uint64
+	maxGasLimit uint64
+
+	// 요청마다 다시 연결하지 않도록 공유하는 체인 backend
+	// dialed는 netUrl로 직접 연결한 경우로, 이때만 재연결과 종료를 담당
+	client        ChainBackend
+	dialed        bool
+	clientMu      sync.RWMutex
+	reconnectedAt time.Time
+	closed        bool // Close 이후에는 재연결하지 않음
+
+	// 동시 전송시 nonce가 겹치지 않도록 주소별로 발급
+	nonces *nonceManager
+
+	// 사용할 수 있는 토큰 목록, tokenAddress가 기본 토큰
+	tokens *tokenRegistry
+
+	// 이벤트 구독용 websocket rpc, wsUrl이 없으면 공유 backend로 구독
+	wsUrl    string
+	wsClient *ethclient.Client
+	wsMu     sync.Mutex
+
+	// 전송 내역 조회시 FilterTransfer 한 번에 조회하는 블록 수
+	logRange uint64
 
-func (p *Model) SearchTokenSymbolByTokenNameModel(tokenName string) (string, error) {
+	// deploy로 배포한 컨트랙트를 기록하는 파일
+	statePath string
+	stateMu   sync.Mutex
 
-	// 블록체인 네트워크와 연결할 클라이언트를 생성하기 위한 rpc url 연결
-	client, err := ethclient.Dial(p.netUrl)
+	// 입금 알림 webhook
+	webhooks *webhookManager
+
+	// 토큰 이벤트 로컬 색인, 사용하지 않으면 nil
+	indexer *indexer
+
+	// API key 인증
+	authEnabled bool
+	apiKeys     *apiKeyStore
+
+	// 서명된 요청 검증
+	verifier *requestVerifier
+
+	// 서비스 지갑 전송 한도
+	policy *policyEngine
+
+	// 서비스 지갑 전송 대상 주소 제한
+	addressList *addressListManager
+}
+
+// backend가 nil이면 config의 netUrl로 연결, 테스트에서는 SimulatedBackend 등을 주입
+func NewModel(cfg *conf.Config, backend ChainBackend) (*Model, error) {
+	r, err := newChainModel(cfg, backend)
 	if err != nil {
-		log.Error("client 에러", err.Error())
+		return nil, err
 	}
 
-	// 토큰 컨트랙트 어드레스
-	tokenAddress := common.HexToAddress(p.tokenAddress)
-	instance, err := cont.NewContractsCaller(tokenAddress, client)
-	if err != nil {
-		log.Error("NewContractsCaller 에러", err.Error())
+	if r.webhooks, err = newWebhookManager(cfg); err != nil {
+		return nil, err
 	}
 
-	contractTokenName, err := instance.Name(&bind.CallOpts{})
-	if err != nil {
-		log.Error("Token Name 조회 에러", err.Error())
-		return "", err
-	} else if contractTokenName != tokenName {
-		log.Error("Token Name 불일치")
-		return "", errors.New("token Name 불일치")
+	if r.addressList, err = newAddressListManager(cfg); err != nil {
+		return nil, err
 	}
 
-	symbol, err := instance.Symbol(&bind.CallOpts{})
-	if err != nil {
-		log.Error("Symbol 조회 에러", err.Error())
-		return "", err
+	r.authEnabled = cfg.Auth.Enabled
+	if r.apiKeys, err = newAPIKeyStore(cfg.Auth.Path); err != nil {
+		return nil, err
+	}
+	if r.verifier, err = newRequestVerifier(cfg); err != nil {
+		return nil, err
+	}
+
+	if r.indexer, err = newIndexer(cfg); err != nil {
+		return nil, err
+	}
 
+	// 기본 토큰, [[tokens]]에 설정된 토큰, statePath에 기록된 배포 토큰의 name, symbol, decimals를 조회해 레지스트리에 등록
+	// registry path에 기록된 실행 중 등록, 제거를 마지막에 반영
+	if err := r.loadTokens(cfg); err != nil {
+		return nil, err
 	}
 
-	return symbol, nil
+	// 전송 한도, 토큰별 rule은 레지스트리의 decimals로 확인
+	if r.policy, err = newPolicyEngine(cfg); err != nil {
+		return nil, err
+	}
+	if err := r.policy.validate(r.tokens); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
-func (p *Model) SearchTokenBalanceByAddressModel(address string) (*big.Int, error) {
+// deploy 명령용 Model, 체인 연결과 서비스 지갑 nonce만 준비
+// 실행 중인 서버와 같은 config를 사용하므로 색인 저장소(LevelDB), webhook, API key, 전송 한도 같은 서버 상태는 열지 않음
+func NewDeployModel(cfg *conf.Config, backend ChainBackend) (*Model, error) {
+	return newChainModel(cfg, backend)
+}
 
-	// 블록체인 네트워크와 연결할 클라이언트를 생성하기 위한 rpc url 연결
-	client, err := ethclient.Dial(p.netUrl)
-	if err != nil {
-		log.Error("client 에러", err.Error())
+// config의 체인 연결, 수수료, 영수증 대기 설정으로 Model 생성, 서비스 지갑의 nonce를 체인과 동기화
+func newChainModel(cfg *conf.Config, backend ChainBackend) (*Model, error) {
+	r := &Model{nonces: newNonceManager(), tokens: newTokenRegistry()}
+	r.privateKey = cfg.Contract.PrivateKey
+	r.netUrl = cfg.Contract.NetUrl
+	r.wsUrl = cfg.Contract.WsUrl
+	r.transactionHash = cfg.Contract.TransactionHash
+	r.tokenAddress = cfg.Contract.TokenAddress
+	r.constructorAddress = cfg.Contract.ConstructorAddress
+	r.statePath = cfg.Contract.StatePath
+	r.tokens.path = cfg.Registry.Path
+
+	// 영수증 대기 기본값
+	r.waitConfirmations = cfg.Contract.WaitConfirmations
+	if r.waitConfirmations == 0 {
+		r.waitConfirmations = defaultWaitConfirmations
+	}
+	r.waitTimeout = time.Duration(cfg.Contract.WaitTimeout) * time.Second
+	if r.waitTimeout <= 0 {
+		r.waitTimeout = defaultWaitTimeout
 	}
 
-	// 토큰 컨트랙트 어드레스
-	tokenAddress := common.HexToAddress(p.tokenAddress)
-	instance, err := cont.NewContractsCaller(tokenAddress, client)
-	if err != nil {
-		log.Error("NewContractsCaller 에러", err.Error())
+	// dynamic fee 설정, multiplier는 baseFee 아래로 내려가지 않도록 1 이상으로 제한
+	r.dynamicFee = cfg.Contract.DynamicFee
+	if cfg.Contract.MaxFeePerGas > 0 {
+		r.maxFeePerGas = big.NewInt(cfg.Contract.MaxFeePerGas)
+	}
+	if cfg.Contract.MaxPriorityFeePerGas > 0 {
+		r.maxPriorityFeePerGas = big.NewInt(cfg.Contract.MaxPriorityFeePerGas)
+	}
+	r.maxFeeMultiplier = cfg.Contract.MaxFeeMultiplier
+	if r.maxFeeMultiplier <= 0 {
+		r.maxFeeMultiplier = defaultMaxFeeMultiplier
+	} else if r.maxFeeMultiplier < 1 {
+		r.maxFeeMultiplier = 1
 	}
 
-	targetAddress := common.HexToAddress(address)
-	balance, err := instance.BalanceOf(&bind.CallOpts{}, targetAddress)
-	if err != nil {
-		log.Error("balance 조회 에러", err.Error())
-		return balance, err
+	// 가스 예측 여유분과 최대값
+	r.gasMargin = cfg.Contract.GasMargin
+	if r.gasMargin == 0 {
+		r.gasMargin = defaultGasMargin
 	}
+	r.maxGasLimit = cfg.Contract.MaxGasLimit
 
-	return balance, nil
-}
+	r.logRange = cfg.Contract.LogRange
+	if r.logRange == 0 {
+		r.logRange = defaultLogRange
+	}
 
-func (p *Model) SendTokenByAddressModel(targetAddress string, privateKeyParam string) error {
+	if backend != nil {
+		r.client = backend
+	} else {
+		// 블록체인 네트워크와 연결할 클라이언트를 생성하고 ChainID로 연결 확인
+		client, err := dialClient(r.netUrl)
+		if err != nil {
+			return nil, err
+		}
+		r.client = client
+		r.dialed = true
+	}
 
-	// 블록체인 네트워크와 연결할 클라이언트를 생성하기 위한 rpc url 연결
-	client, err := ethclient.Dial(p.netUrl)
-	if err != nil {
-		log.Error("client 에러", err.Error())
-		return err
+	// 서비스 지갑의 nonce를 시작 시점에 체인과 동기화
+	if r.privateKey != "" {
+		privateKey, err := crypto.HexToECDSA(r.privateKey)
+		if err != nil {
+			return nil, err
+		}
+		from := crypto.PubkeyToAddress(privateKey.PublicKey)
+		if err := r.nonces.resync(context.Background(), r.client, from, true); err != nil {
+			return nil, err
+		}
 	}
 
-	// 토큰 컨트랙트 어드레스
-	tokenAddress := common.HexToAddress(p.tokenAddress)
+	return r, nil
+}
 
-	if privateKeyParam == "" {
-		privateKeyParam = p.privateKey
+func (p *Model) loadTokens(cfg *conf.Config) error {
+	ctx := context.Background()
+	if p.tokenAddress != "" {
+		info, err := p.fetchTokenInfo(ctx, p.tokenAddress)
+		if err != nil {
+			return err
+		}
+		info.Default = true
+		if err := p.tokens.add(info); err != nil {
+			return err
+		}
 	}
-	// 기본키 지정
-	privateKey, err := crypto.HexToECDSA(privateKeyParam)
-	if err != nil {
-		log.Error("HexToECDSA 에러", err.Error())
+	for _, token := range cfg.Tokens {
+		if p.tokens.has(token.Address) {
+			continue
+		}
+		info, err := p.fetchTokenInfo(ctx, token.Address)
+		if err != nil {
+			return err
+		}
+		if err := p.tokens.add(info); err != nil {
+			return err
+		}
+	}
+	if err := p.loadDeployedTokens(ctx); err != nil {
 		return err
 	}
+	return p.loadRegistryState(ctx)
+}
 
-	// privatekey로부터 publickey를 거쳐 자신의 address 변환
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Error("fail convert, publickey")
-		return errors.New("fail convert")
+// statePath에 기록된 현재 체인의 배포 토큰 등록
+// tokenAddress가 비어있으면 가장 최근에 배포한 토큰을 기본 토큰으로 사용
+func (p *Model) loadDeployedTokens(ctx context.Context) error {
+	if p.statePath == "" {
+		return nil
 	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-
-	// 현재 계정의 nonce를 가져옴. 다음 트랜잭션에서 사용할 nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	state, err := readState(p.statePath)
 	if err != nil {
-		log.Error("PendingNonceAt 에러", err.Error())
 		return err
 	}
-
-	// 전송할 양, gasLimit, gasPrice 설정. 추천되는 gasPrice를 가져옴
-	value := big.NewInt(700000000000000000)
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	if len(state.Deployments) == 0 {
+		return nil
+	}
+	chainID, err := p.backend().NetworkID(ctx)
 	if err != nil {
-		log.Error("SuggestGasPrice 에러", err.Error())
 		return err
 	}
 
-	// 보낼 주소
-	toAddress := common.HexToAddress(targetAddress)
-
-	// 컨트랙트 전송시 사용할 함수명
-	transferFnSignature := []byte("transfer(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-	fmt.Println(hexutil.Encode(methodID))
-
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
-	paddedAmount := common.LeftPadBytes(value.Bytes(), 32)
-	zvalue := big.NewInt(0)
-
-	//컨트랙트 전송 정보 입력
-	var pdata []byte
-	pdata = append(pdata, methodID...)
-	pdata = append(pdata, paddedAddress...)
-	pdata = append(pdata, paddedAmount...)
-
-	gasLimit := uint64(200000)
-
-	// 트랜잭션 생성
-	tx := types.NewTransaction(nonce, tokenAddress, zvalue, gasLimit, gasPrice, pdata)
-	chainID, err := client.NetworkID(context.Background())
-	if err != nil {
-		log.Error("트랜잭션 생성 에러", err.Error())
-		return err
+	for i := len(state.Deployments) - 1; i >= 0; i-- {
+		d := state.Deployments[i]
+		if d.ChainID != chainID.String() || p.tokens.has(d.Address) {
+			continue
+		}
+		info, err := p.fetchTokenInfo(ctx, d.Address)
+		if err != nil {
+			return err
+		}
+		if !p.tokens.hasDefault() {
+			info.Default = true
+			p.tokenAddress = info.Address
+			p.constructorAddress = d.Deployer
+			p.transactionHash = d.TxHash
+		}
+		if err := p.tokens.add(info); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// 트랜잭션 서명
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+// token 토큰의 이름이 tokenName과 같으면 symbol 반환
+func (p *Model) SearchTokenSymbolByTokenNameModel(token string, tokenName string) (string, error) {
+	info, err := p.resolveToken(token)
 	if err != nil {
-		log.Error("트랜잭션 서명 에러", err.Error())
-		return err
+		return "", err
 	}
+	if info.Name != tokenName {
+		log.Error("Token Name 불일치")
+		return "", errors.New("token Name 불일치")
+	}
+
+	return info.Symbol, nil
+}
 
-	// 트랜잭션 전송
-	err = client.SendTransaction(context.Background(), signedTx)
+func (p *Model) SendTokenByAddressModel(req *SendRequest) (*SendResult, error) {
+
+	// 보낼 주소, zero address, 토큰 컨트랙트, deny 목록의 주소로는 보내지 않음
+	toAddress, err := parseAddress("address", req.TargetAddress)
 	if err != nil {
-		log.Error("트랜잭션 전송 에러", err.Error())
-		return err
+		return nil, err
+	}
+	if err := p.checkDestination(toAddress); err != nil {
+		log.Error("전송 대상 차단", err.Error())
+		return nil, err
 	}
 
-	//tx.hash를 이용해 전송결과를 확인
-	fmt.Printf("tx sent: %s", signedTx.Hash().Hex())
-	return nil
+	// 생성된 바인딩의 transfer(address,uint256)로 트랜잭션 생성 및 전송, 서비스 지갑 전송 한도 적용
+	return p.transactToken(req, tokenTx{method: "transfer", to: toAddress, limited: true}, func(transactor *cont.ContractsTransactor, opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+		return transactor.Transfer(opts, toAddress, value)
+	})
 }
 
-func (p *Model) SendWemixCoinByAddressModel(targetAddress string, privateKeyParam string) error {
+func (p *Model) SendWemixCoinByAddressModel(req *SendRequest) (*SendResult, error) {
 
-	// 블록체인 네트워크와 연결할 클라이언트를 생성하기 위한 rpc url 연결
-	client, err := ethclient.Dial(p.netUrl)
+	// 보낼 주소, zero address, 토큰 컨트랙트, deny 목록의 주소로는 보내지 않음
+	toAddress, err := parseAddress("address", req.TargetAddress)
 	if err != nil {
-		log.Error("client 에러", err.Error())
-		return err
+		return nil, err
 	}
-
-	if privateKeyParam == "" {
-		privateKeyParam = p.privateKey
+	if err := p.checkDestination(toAddress); err != nil {
+		log.Error("전송 대상 차단", err.Error())
+		return nil, err
 	}
-	// 기본키 지정
-	privateKey, err := crypto.HexToECDSA(privateKeyParam)
+
+	// 전송량은 위믹스 decimals 기준으로 변환
+	value, err := ParseAmount(req.Amount, coinDecimals, req.BaseUnit)
 	if err != nil {
-		log.Error("HexToECDSA 에러", err.Error())
-		return err
+		log.Error("전송량 변환 에러", err.Error())
+		return nil, err
 	}
 
-	// privatekey로부터 publickey를 거쳐 자신의 address 변환
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		fmt.Println("fail convert, publickey")
-	}
-	// 보낼 address 설정
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	client := p.backend()
+	ctx := context.Background()
 
-	// 현재 계정의 nonce를 가져옴. 다음 트랜잭션에서 사용할 nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	// 기본키 지정, privatekey로부터 자신의 address 변환
+	privateKey, err := p.signingKey()
 	if err != nil {
-		log.Error("PendingNonceAt 에러", err.Error())
-		return err
+		return nil, err
 	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	// 전송할 양, gasLimit, gasPrice 설정. 추천되는 gasPrice를 가져옴
-	value := big.NewInt(700000000000000000)
-	gasLimit := uint64(21000)
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	// 수수료 설정. dynamic fee 또는 추천되는 gasPrice를 가져옴
+	fee, err := p.suggestFee(ctx, client)
 	if err != nil {
-		log.Error("SuggestGasPrice 에러", err.Error())
-		return err
+		return nil, err
 	}
 
-	// 보낼 주소
-	toAddress := common.HexToAddress(targetAddress)
-
-	// 트랜잭션 생성
-	var data []byte
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, gasPrice, data)
-	chainID, err := client.NetworkID(context.Background())
+	// 받는 주소가 컨트랙트 지갑일 수 있어 고정값 대신 gasLimit 예측
+	gasLimit, err := p.estimateGas(ctx, client, fromAddress, &toAddress, value, nil)
 	if err != nil {
-		log.Error("트랜잭션 생성 에러", err.Error())
-		return err
+		return nil, err
 	}
 
-	// 트랜잭션 서명
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	chainID, err := client.NetworkID(ctx)
 	if err != nil {
-		log.Error("트랜잭션 서명 에러", err.Error())
-		return err
+		log.Error("NetworkID 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
 	}
 
-	// RLP 인코딩 전 트랜잭션 묶음. 현재는 1개의 트랜잭션
-	ts := types.Transactions{signedTx}
-	// RLP 인코딩
-	rawTxBytes, _ := rlp.EncodeToBytes(ts[0])
-	rawTxHex := hex.EncodeToString(rawTxBytes)
-	rTxBytes, err := hex.DecodeString(rawTxHex)
+	// 서비스 지갑 전송 한도 확인
+	release, err := p.policy.reserve(nil, req.APIKey, toAddress, value, time.Now())
 	if err != nil {
-		log.Error("RLP 인코딩 에러", err.Error())
-		return err
+		log.Error("전송 한도 초과", err.Error())
+		return nil, err
 	}
 
-	// RLP 디코딩
-	rlp.DecodeBytes(rTxBytes, &tx)
-
-	// 트랜잭션 전송
-	err = client.SendTransaction(context.Background(), tx)
+	// 트랜잭션 생성, 서명 및 전송
+	tx, err := p.signAndSend(ctx, client, privateKey, chainID, func(nonce uint64) (*types.Transaction, error) {
+		return fee.newTx(chainID, nonce, &toAddress, value, gasLimit, nil), nil
+	})
 	if err != nil {
-		log.Error("트랜잭션 전송 에러", err.Error())
-		return err
+		release()
+		return nil, err
 	}
 
 	//tx.hash를 이용해 전송결과를 확인
-	fmt.Printf("tx sent: %s\n", tx.Hash().Hex())
-	return nil
+	result := &SendResult{
+		TxHash:   tx.Hash().Hex(),
+		Type:     tx.Type(),
+		Nonce:    tx.Nonce(),
+		From:     fromAddress.Hex(),
+		To:       toAddress.Hex(),
+		Amount:   value.String(),
+		GasLimit: gasLimit,
+		ChainID:  bigString(chainID),
+	}
+	fee.fill(result)
+	log.InfoFields("coin sent", result.logFields()...)
+
+	// wait 옵션이 있으면 영수증을 받을 때까지 대기
+	if req.Wait != nil {
+		receipt, err := p.waitReceipt(client, tx, req.Wait)
+		result.Receipt = receipt
+		if err != nil {
+			log.Error("영수증 대기 에러", err.Error())
+			return result, err
+		}
+	}
+	return result, nil
 }
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// nonce 조회에 필요한 backend 기능
+type nonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// 주소별 nonce 할당 관리
+// 같은 주소로 동시에 전송해도 nonce가 겹치지 않도록 lock 안에서 발급하고
+// 서명이나 전송에 실패한 nonce는 released에 모아 다음 전송에서 먼저 재사용
+type nonceManager struct {
+	mu       sync.Mutex
+	next     map[common.Address]uint64
+	released map[common.Address][]uint64
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{
+		next:     make(map[common.Address]uint64),
+		released: make(map[common.Address][]uint64),
+	}
+}
+
+// 다음에 사용할 nonce 발급, 처음 사용하는 주소는 체인에서 동기화
+func (m *nonceManager) acquire(ctx context.Context, src nonceSource, addr common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if gaps := m.released[addr]; len(gaps) > 0 {
+		nonce := gaps[0]
+		m.released[addr] = gaps[1:]
+		return nonce, nil
+	}
+
+	next, ok := m.next[addr]
+	if !ok {
+		pending, err := src.PendingNonceAt(ctx, addr)
+		if err != nil {
+			return 0, err
+		}
+		next = pending
+	}
+	m.next[addr] = next + 1
+	return next, nil
+}
+
+// 사용하지 못한 nonce 반환
+// 마지막으로 발급한 nonce면 되돌리고, 중간 값이면 빈 자리로 남겨 다음 발급에서 재사용
+func (m *nonceManager) release(addr common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next, ok := m.next[addr]
+	if !ok || nonce >= next {
+		return
+	}
+
+	gaps := append(m.released[addr], nonce)
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+
+	// 끝에서부터 연속된 빈 자리는 next를 줄여서 정리
+	for len(gaps) > 0 && gaps[len(gaps)-1] == next-1 {
+		gaps = gaps[:len(gaps)-1]
+		next--
+	}
+	m.next[addr] = next
+	m.released[addr] = gaps
+}
+
+// 체인의 pending nonce 기준으로 다시 동기화
+// 다른 요청이 발급받아 아직 전송 중인 nonce가 있을 수 있으므로 next는 pending보다 작을 때만 올리고, 이미 사용된 빈 자리는 버림
+// lower는 노드가 nonce too high로 거부해 발급한 nonce 중간이 비어있는 것이 확실한 경우로, pending으로 되돌리고 빈 자리를 모두 버림
+func (m *nonceManager) resync(ctx context.Context, src nonceSource, addr common.Address, lower bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pending, err := src.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if next, ok := m.next[addr]; lower || !ok || next < pending {
+		m.next[addr] = pending
+	}
+	if lower {
+		delete(m.released, addr)
+		return nil
+	}
+	var gaps []uint64
+	for _, nonce := range m.released[addr] {
+		if nonce >= pending {
+			gaps = append(gaps, nonce)
+		}
+	}
+	m.released[addr] = gaps
+	return nil
+}
+
+// 노드가 nonce 불일치로 트랜잭션을 거부했는지 확인
+func isNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "nonce too high")
+}
+
+// 노드가 발급한 nonce보다 작은 nonce가 비어있어 트랜잭션을 거부했는지 확인
+func isNonceTooHigh(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "nonce too high")
+}
+
+// 노드가 트랜잭션을 확실히 거부했는지 확인, rpc 에러 응답을 받은 경우만 거부로 봄
+// 연결 에러나 timeout은 노드가 트랜잭션을 받았는지 알 수 없고, already known은 이미 txpool에 있으므로 거부가 아님
+func isTxRejected(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return !strings.Contains(msg, "already known") && !strings.Contains(msg, "known transaction")
+}
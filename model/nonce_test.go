@@ -0,0 +1,230 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type fakeNonceSource struct {
+	pending uint64
+	calls   int
+}
+
+func (f *fakeNonceSource) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	f.calls++
+	return f.pending, nil
+}
+
+func TestNonceManagerConcurrentAcquire(t *testing.T) {
+	m := newNonceManager()
+	src := &fakeNonceSource{pending: 5}
+	addr := common.HexToAddress("0x01")
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.acquire(context.Background(), src, addr)
+			if err != nil {
+				t.Errorf("acquire Error: %s", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[nonce] {
+				t.Errorf("nonce %d issued twice", nonce)
+			}
+			seen[nonce] = true
+		}()
+	}
+	wg.Wait()
+
+	for n := uint64(5); n < 55; n++ {
+		if !seen[n] {
+			t.Errorf("nonce %d was not issued", n)
+		}
+	}
+	if src.calls != 1 {
+		t.Errorf("PendingNonceAt calls = %d, want 1", src.calls)
+	}
+}
+
+func TestNonceManagerRelease(t *testing.T) {
+	m := newNonceManager()
+	src := &fakeNonceSource{pending: 0}
+	addr := common.HexToAddress("0x01")
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		m.acquire(ctx, src, addr) // 0, 1, 2
+	}
+
+	// 중간 nonce는 빈 자리로 남아 먼저 재사용
+	m.release(addr, 1)
+	if nonce, _ := m.acquire(ctx, src, addr); nonce != 1 {
+		t.Errorf("acquire after gap = %d, want 1", nonce)
+	}
+	if nonce, _ := m.acquire(ctx, src, addr); nonce != 3 {
+		t.Errorf("acquire = %d, want 3", nonce)
+	}
+
+	// 끝에서부터 연속으로 반환되면 next가 줄어듦
+	m.release(addr, 2)
+	m.release(addr, 3)
+	if nonce, _ := m.acquire(ctx, src, addr); nonce != 2 {
+		t.Errorf("acquire after tail release = %d, want 2", nonce)
+	}
+}
+
+func TestNonceManagerResync(t *testing.T) {
+	m := newNonceManager()
+	src := &fakeNonceSource{pending: 3}
+	addr := common.HexToAddress("0x01")
+	ctx := context.Background()
+
+	m.acquire(ctx, src, addr) // 3
+	m.acquire(ctx, src, addr) // 4
+	m.release(addr, 3)
+
+	src.pending = 10
+	if err := m.resync(ctx, src, addr, false); err != nil {
+		t.Fatalf("resync Error: %s", err)
+	}
+	if nonce, _ := m.acquire(ctx, src, addr); nonce != 10 {
+		t.Errorf("acquire after resync = %d, want 10", nonce)
+	}
+
+	// 전송 중인 nonce가 있으면 pending이 뒤처져도 되돌리지 않음
+	src.pending = 8
+	m.resync(ctx, src, addr, false)
+	if nonce, _ := m.acquire(ctx, src, addr); nonce != 11 {
+		t.Errorf("acquire after lagging resync = %d, want 11", nonce)
+	}
+
+	// nonce too high로 거부되면 pending으로 되돌림
+	m.resync(ctx, src, addr, true)
+	if nonce, _ := m.acquire(ctx, src, addr); nonce != 8 {
+		t.Errorf("acquire after lower resync = %d, want 8", nonce)
+	}
+}
+
+// 체인에 순서대로 도착한 nonce만 pending에 반영하는 fake
+type fakeNonceChain struct {
+	mu       sync.Mutex
+	accepted map[uint64]int
+	pending  uint64
+}
+
+func (f *fakeNonceChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pending, nil
+}
+
+func (f *fakeNonceChain) send(nonce uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accepted[nonce]++
+	for f.accepted[f.pending] > 0 {
+		f.pending++
+	}
+}
+
+// 한 전송이 알 수 없는 에러로 재동기화해도 전송 중인 다른 요청의 nonce를 다시 발급하지 않음
+func TestNonceManagerResyncWhileInFlight(t *testing.T) {
+	m := newNonceManager()
+	chain := &fakeNonceChain{accepted: make(map[uint64]int)}
+	addr := common.HexToAddress("0x01")
+	ctx := context.Background()
+
+	// 먼저 발급받은 요청들은 아직 전송 중
+	var inFlight []uint64
+	for i := 0; i < 5; i++ {
+		nonce, _ := m.acquire(ctx, chain, addr)
+		inFlight = append(inFlight, nonce)
+	}
+
+	var wg sync.WaitGroup
+	for _, nonce := range inFlight {
+		wg.Add(1)
+		go func(nonce uint64) {
+			defer wg.Done()
+			// 노드가 받았는지 알 수 없는 에러로 실패한 요청
+			if nonce == 0 {
+				if err := m.resync(ctx, chain, addr, false); err != nil {
+					t.Errorf("resync Error: %s", err)
+				}
+				return
+			}
+			chain.send(nonce)
+		}(nonce)
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.acquire(ctx, chain, addr)
+			if err != nil {
+				t.Errorf("acquire Error: %s", err)
+				return
+			}
+			chain.send(nonce)
+		}()
+	}
+	wg.Wait()
+
+	for nonce, n := range chain.accepted {
+		if n > 1 {
+			t.Errorf("nonce %d sent %d times", nonce, n)
+		}
+	}
+	if len(chain.accepted) != 9 {
+		t.Errorf("accepted = %v, want 9 distinct nonces", chain.accepted)
+	}
+}
+
+func TestIsNonceError(t *testing.T) {
+	if !isNonceError(errors.New("nonce too low")) || !isNonceError(errors.New("Nonce too high: address 0x..")) {
+		t.Error("nonce errors not detected")
+	}
+	if isNonceError(errors.New("insufficient funds for gas * price + value")) || isNonceError(nil) {
+		t.Error("unexpected nonce error")
+	}
+}
+
+type rpcError struct {
+	msg string
+}
+
+func (e *rpcError) Error() string  { return e.msg }
+func (e *rpcError) ErrorCode() int { return -32000 }
+
+func TestIsTxRejected(t *testing.T) {
+	for _, err := range []error{
+		&rpcError{"insufficient funds for gas * price + value"},
+		fmt.Errorf("wrapped: %w", &rpcError{"nonce too low"}),
+	} {
+		if !isTxRejected(err) {
+			t.Errorf("%v not rejected", err)
+		}
+	}
+	for _, err := range []error{
+		&rpcError{"already known"},
+		context.DeadlineExceeded,
+		io.ErrUnexpectedEOF,
+		errors.New("502 Bad Gateway"),
+	} {
+		if isTxRejected(err) {
+			t.Errorf("%v rejected", err)
+		}
+	}
+}
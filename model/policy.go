@@ -0,0 +1,282 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	conf "go-contract/config"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// 일일 한도를 계산하는 기간, 달력 기준이 아닌 최근 24시간
+const policyWindow = 24 * time.Hour
+
+// rule의 token 값, coin은 WEMIX 코인, *는 rule이 없는 모든 토큰
+const (
+	policyCoin     = "coin"
+	policyWildcard = "*"
+)
+
+// 전송 한도 이름
+const (
+	LimitMaxPerTransfer      = "maxPerTransfer"
+	LimitDailyPerKey         = "dailyPerKey"
+	LimitDailyPerDestination = "dailyPerDestination"
+	LimitDailyTotal          = "dailyTotal"
+)
+
+var (
+	// 전송 한도를 넘은 경우 반환, 자세한 내용은 PolicyError
+	ErrPolicyLimit = errors.New("transfer limit exceeded")
+	// config의 한도 값이 잘못된 경우 반환
+	ErrInvalidPolicy = errors.New("invalid transfer policy")
+)
+
+// 전송 한도 초과 정보, 금액은 최소 단위 정수 문자열
+type PolicyError struct {
+	Limit  string `json:"limit"`  // 초과한 한도 이름
+	Asset  string `json:"asset"`  // coin 또는 토큰 주소
+	Max    string `json:"max"`    // 한도
+	Used   string `json:"used"`   // 최근 24시간 사용량, maxPerTransfer는 0
+	Amount string `json:"amount"` // 요청한 전송량
+	// 이 시각 이후에는 같은 요청이 한도 안에 들어옴, 전송량 자체가 한도보다 크면 nil
+	ResetAt *time.Time `json:"resetAt,omitempty"`
+}
+
+func (e *PolicyError) Error() string {
+	msg := fmt.Sprintf("%s: %s %s, used %s, amount %s", ErrPolicyLimit, e.Limit, e.Max, e.Used, e.Amount)
+	if e.ResetAt != nil {
+		msg += ", resets at " + e.ResetAt.Format(time.RFC3339)
+	}
+	return msg
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyLimit
+}
+
+// 서비스 지갑에서 나간 전송 기록
+type transferRecord struct {
+	At     time.Time `json:"at"`
+	Asset  string    `json:"asset"`
+	APIKey string    `json:"apiKey"`
+	To     string    `json:"to"`
+	Amount string    `json:"amount"`
+}
+
+type policyRule struct {
+	token  string
+	limits map[string]string // 한도 이름별 소수 문자열, 비어있으면 제한 없음
+}
+
+// 토큰별 전송 한도 확인과 최근 24시간 전송 기록
+type policyEngine struct {
+	mu      sync.Mutex
+	path    string
+	rules   []policyRule
+	records []*transferRecord
+}
+
+func newPolicyEngine(cfg *conf.Config) (*policyEngine, error) {
+	e := &policyEngine{path: cfg.Policy.Path}
+	for _, r := range cfg.Policy.Rules {
+		if r.Token == "" {
+			return nil, fmt.Errorf("%w: empty token", ErrInvalidPolicy)
+		}
+		e.rules = append(e.rules, policyRule{token: r.Token, limits: map[string]string{
+			LimitMaxPerTransfer:      r.MaxPerTransfer,
+			LimitDailyPerKey:         r.DailyPerKey,
+			LimitDailyPerDestination: r.DailyPerDestination,
+			LimitDailyTotal:          r.DailyTotal,
+		}})
+	}
+	if e.path != "" {
+		if err := readJSON(e.path, &e.records); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// 한도 값 형식 확인, 토큰 rule은 레지스트리의 decimals 기준으로, coin과 * rule은 코인 decimals로 확인
+// 레지스트리에 없는 토큰을 지정한 rule은 적용되지 않으므로 설정 에러
+func (e *policyEngine) validate(tokens *tokenRegistry) error {
+	for _, rule := range e.rules {
+		decimals := uint8(coinDecimals)
+		if rule.token != policyCoin && rule.token != policyWildcard {
+			info, err := tokens.get(rule.token)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, rule.token, err)
+			}
+			decimals = info.Decimals
+		}
+		for name, v := range rule.limits {
+			if v == "" {
+				continue
+			}
+			if _, err := ParseAmount(v, decimals, false); err != nil {
+				return fmt.Errorf("%w: %s %s: %v", ErrInvalidPolicy, rule.token, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// token에 적용할 rule, token이 nil이면 코인
+// 토큰은 주소 또는 심볼이 같은 rule을 먼저 찾고, 없으면 * rule 사용
+func (e *policyEngine) ruleFor(token *TokenInfo) *policyRule {
+	var wildcard *policyRule
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if token == nil {
+			if rule.token == policyCoin {
+				return rule
+			}
+			continue
+		}
+		if strings.EqualFold(rule.token, token.Address) || strings.EqualFold(rule.token, token.Symbol) {
+			return rule
+		}
+		if rule.token == policyWildcard && wildcard == nil {
+			wildcard = rule
+		}
+	}
+	return wildcard
+}
+
+// 최근 24시간이 지난 기록 삭제, 호출하는 쪽에서 mu를 잡고 있어야 함
+func (e *policyEngine) prune(now time.Time) {
+	kept := e.records[:0]
+	for _, r := range e.records {
+		if now.Sub(r.At) < policyWindow {
+			kept = append(kept, r)
+		}
+	}
+	e.records = kept
+}
+
+// 호출하는 쪽에서 mu를 잡고 있어야 함
+func (e *policyEngine) save() error {
+	if e.path == "" {
+		return nil
+	}
+	return writeJSON(e.path, e.records)
+}
+
+// 한도를 확인하고 전송 기록을 남김, 전송에 실패하면 반환된 release로 기록을 되돌림
+// token이 nil이면 코인 전송
+func (e *policyEngine) reserve(token *TokenInfo, apiKey string, to common.Address, value *big.Int, now time.Time) (func(), error) {
+	rule := e.ruleFor(token)
+	if rule == nil {
+		return func() {}, nil
+	}
+	asset, decimals := policyCoin, uint8(coinDecimals)
+	if token != nil {
+		asset, decimals = token.Address, token.Decimals
+	}
+	limits := make(map[string]*big.Int)
+	for name, v := range rule.limits {
+		if v == "" {
+			continue
+		}
+		max, err := ParseAmount(v, decimals, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %s: %v", ErrInvalidPolicy, rule.token, name, err)
+		}
+		limits[name] = max
+	}
+
+	if max, ok := limits[LimitMaxPerTransfer]; ok && value.Cmp(max) > 0 {
+		return nil, &PolicyError{Limit: LimitMaxPerTransfer, Asset: asset, Max: max.String(), Used: "0", Amount: value.String()}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.prune(now)
+
+	record := &transferRecord{At: now.UTC(), Asset: asset, APIKey: apiKey, To: to.Hex(), Amount: value.String()}
+	for _, limit := range []struct {
+		name  string
+		match func(r *transferRecord) bool
+	}{
+		{LimitDailyPerKey, func(r *transferRecord) bool { return r.APIKey == apiKey }},
+		{LimitDailyPerDestination, func(r *transferRecord) bool { return r.To == record.To }},
+		{LimitDailyTotal, func(r *transferRecord) bool { return true }},
+	} {
+		max, ok := limits[limit.name]
+		if !ok {
+			continue
+		}
+		var matched []*transferRecord
+		used := new(big.Int)
+		for _, r := range e.records {
+			if r.Asset == asset && limit.match(r) {
+				matched = append(matched, r)
+				used.Add(used, recordAmount(r))
+			}
+		}
+		if new(big.Int).Add(used, value).Cmp(max) <= 0 {
+			continue
+		}
+		return nil, &PolicyError{
+			Limit:   limit.name,
+			Asset:   asset,
+			Max:     max.String(),
+			Used:    used.String(),
+			Amount:  value.String(),
+			ResetAt: resetAt(matched, used, value, max),
+		}
+	}
+
+	e.records = append(e.records, record)
+	if err := e.save(); err != nil {
+		e.records = e.records[:len(e.records)-1]
+		return nil, err
+	}
+	return func() { e.release(record) }, nil
+}
+
+// 오래된 기록부터 24시간이 지나며 빠질 때, used + value가 max 이하가 되는 가장 이른 시각
+func resetAt(records []*transferRecord, used *big.Int, value *big.Int, max *big.Int) *time.Time {
+	if value.Cmp(max) > 0 {
+		return nil
+	}
+	remaining := new(big.Int).Set(used)
+	for _, r := range records {
+		remaining.Sub(remaining, recordAmount(r))
+		if new(big.Int).Add(remaining, value).Cmp(max) <= 0 {
+			at := r.At.Add(policyWindow)
+			return &at
+		}
+	}
+	return nil
+}
+
+func recordAmount(r *transferRecord) *big.Int {
+	v, ok := new(big.Int).SetString(r.Amount, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// 전송하지 못한 기록 삭제
+func (e *policyEngine) release(record *transferRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, r := range e.records {
+		if r == record {
+			e.records = append(e.records[:i], e.records[i+1:]...)
+			break
+		}
+	}
+	if err := e.save(); err != nil {
+		log.Error("전송 기록 저장 에러", err.Error())
+	}
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestPolicy(t *testing.T, path string) *policyEngine {
+	t.Helper()
+	cfg := new(conf.Config)
+	cfg.Policy.Path = path
+	cfg.Policy.Rules = append(cfg.Policy.Rules, struct {
+		Token               string
+		MaxPerTransfer      string
+		DailyPerKey         string
+		DailyPerDestination string
+		DailyTotal          string
+	}{Token: "YKK", MaxPerTransfer: "5", DailyPerKey: "8", DailyPerDestination: "6", DailyTotal: "10"})
+	e, err := newPolicyEngine(cfg)
+	if err != nil {
+		t.Fatalf("newPolicyEngine Error: %s", err)
+	}
+	return e
+}
+
+func TestPolicyEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	e := newTestPolicy(t, path)
+	token := &TokenInfo{Address: common.HexToAddress("0x01").Hex(), Symbol: "ykk", Decimals: 0}
+	a, b := common.HexToAddress("0xa"), common.HexToAddress("0xb")
+	now := time.Unix(1700000000, 0)
+
+	limitOf := func(_ func(), err error) *PolicyError {
+		var policyErr *PolicyError
+		if !errors.As(err, &policyErr) || !errors.Is(err, ErrPolicyLimit) {
+			t.Fatalf("err = %v, want PolicyError", err)
+		}
+		return policyErr
+	}
+
+	// rule이 없는 토큰과 코인은 제한 없음
+	if _, err := e.reserve(&TokenInfo{Address: common.HexToAddress("0x02").Hex(), Symbol: "OTHER"}, "k1", a, big.NewInt(100), now); err != nil {
+		t.Errorf("unlimited token err = %v", err)
+	}
+	if _, err := e.reserve(nil, "k1", a, big.NewInt(100), now); err != nil {
+		t.Errorf("unlimited coin err = %v", err)
+	}
+
+	if perr := limitOf(e.reserve(token, "k1", a, big.NewInt(6), now)); perr.Limit != LimitMaxPerTransfer || perr.ResetAt != nil {
+		t.Errorf("max per transfer = %+v", perr)
+	}
+	if _, err := e.reserve(token, "k1", a, big.NewInt(4), now); err != nil {
+		t.Fatalf("reserve Error: %s", err)
+	}
+	if _, err := e.reserve(token, "k1", a, big.NewInt(2), now.Add(time.Hour)); err != nil {
+		t.Fatalf("reserve Error: %s", err)
+	}
+	// a로 최근 24시간 6을 보냄, 첫 전송이 24시간 지나면 다시 가능
+	perr := limitOf(e.reserve(token, "k2", a, big.NewInt(1), now.Add(2*time.Hour)))
+	if perr.Limit != LimitDailyPerDestination || perr.Used != "6" || perr.ResetAt == nil || !perr.ResetAt.Equal(now.Add(policyWindow)) {
+		t.Errorf("per destination = %+v", perr)
+	}
+	// k1은 6을 보냈으므로 3은 key 한도 초과, 첫 전송 4가 빠지면 가능
+	perr = limitOf(e.reserve(token, "k1", b, big.NewInt(3), now.Add(2*time.Hour)))
+	if perr.Limit != LimitDailyPerKey || perr.ResetAt == nil || !perr.ResetAt.Equal(now.Add(policyWindow)) {
+		t.Errorf("per key = %+v", perr)
+	}
+	// 실패한 전송은 되돌려 한도에 포함하지 않음
+	release, err := e.reserve(token, "k2", b, big.NewInt(4), now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("reserve Error: %s", err)
+	}
+	release()
+	if _, err := e.reserve(token, "k2", b, big.NewInt(4), now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("reserve after release Error: %s", err)
+	}
+	// 전체 10 중 10을 사용
+	if perr := limitOf(e.reserve(token, "k3", common.HexToAddress("0xc"), big.NewInt(1), now.Add(3*time.Hour))); perr.Limit != LimitDailyTotal || perr.Used != "10" {
+		t.Errorf("total = %+v", perr)
+	}
+
+	// 재시작해도 기록이 남고, 24시간이 지난 기록은 빠짐
+	e = newTestPolicy(t, path)
+	if perr := limitOf(e.reserve(token, "k3", common.HexToAddress("0xc"), big.NewInt(1), now.Add(3*time.Hour))); perr.Limit != LimitDailyTotal {
+		t.Errorf("reloaded = %+v", perr)
+	}
+	if _, err := e.reserve(token, "k3", common.HexToAddress("0xc"), big.NewInt(4), now.Add(policyWindow)); err != nil {
+		t.Errorf("after window err = %v", err)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	e := newTestPolicy(t, "")
+	tokens := newTokenRegistry()
+	if err := e.validate(tokens); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("unregistered token err = %v, want ErrInvalidPolicy", err)
+	}
+
+	tokens.add(&TokenInfo{Address: common.HexToAddress("0x01").Hex(), Symbol: "YKK", Decimals: 0})
+	if err := e.validate(tokens); err != nil {
+		t.Errorf("validate Error: %s", err)
+	}
+	// 토큰 decimals보다 긴 소수 한도는 설정 에러
+	e.rules[0].limits[LimitMaxPerTransfer] = "0.5"
+	if err := e.validate(tokens); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("fractional limit err = %v, want ErrInvalidPolicy", err)
+	}
+}
@@ -0,0 +1,299 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// 서명된 트랜잭션을 디코딩할 수 없거나 서명이 잘못된 경우 반환
+	ErrInvalidRawTx = errors.New("invalid raw transaction")
+	// 트랜잭션의 체인 ID가 연결된 체인과 다르거나 체인 ID 없이 서명된 경우 반환
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+	// 서명한 계정이 요청한 from과 다른 경우 반환
+	ErrSenderMismatch = errors.New("sender mismatch")
+	// 받는 주소가 등록된 토큰의 쓰기 함수 호출이나 코인 전송이 아닌 경우 반환
+	ErrInvalidTxTarget = errors.New("invalid transaction target")
+)
+
+// 서명 전 트랜잭션, 클라이언트가 RawTx를 디코딩하거나 SigningHash에 직접 서명한 뒤 broadcast로 전송
+// legacy 트랜잭션은 gasPrice, dynamic fee 트랜잭션은 maxFeePerGas, maxPriorityFeePerGas를 채움
+type UnsignedTx struct {
+	Type                 uint8  `json:"type"`
+	ChainID              string `json:"chainId"`
+	Nonce                uint64 `json:"nonce"`
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+	Value                string `json:"value"`
+	Data                 string `json:"data"`
+	GasLimit             uint64 `json:"gasLimit"`
+	GasPrice             string `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"`
+	RawTx                string `json:"rawTx"`
+	SigningHash          string `json:"signingHash"`
+
+	// 토큰 전송이면 토큰 주소와 받는 주소, 전송량
+	Token     string `json:"token,omitempty"`
+	Recipient string `json:"recipient"`
+	Amount    string `json:"amount"`
+}
+
+// from 계정이 서명할 토큰 transfer 트랜잭션 생성
+func (p *Model) BuildTokenTxModel(req *SendRequest, from string) (*UnsignedTx, error) {
+	fromAddress, err := parseAddress("from", from)
+	if err != nil {
+		return nil, err
+	}
+	toAddress, err := parseAddress("address", req.TargetAddress)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.resolveToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ParseAmount(req.Amount, token.Decimals, req.BaseUnit)
+	if err != nil {
+		log.Error("전송량 변환 에러", err.Error())
+		return nil, err
+	}
+
+	parsed, err := cont.ContractsMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	data, err := parsed.Pack("transfer", toAddress, value)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddress := common.HexToAddress(token.Address)
+	unsigned, err := p.buildTx(fromAddress, &tokenAddress, new(big.Int), data)
+	if err != nil {
+		return nil, err
+	}
+	unsigned.Token = tokenAddress.Hex()
+	unsigned.Recipient = toAddress.Hex()
+	unsigned.Amount = value.String()
+	return unsigned, nil
+}
+
+// from 계정이 서명할 WEMIX 코인 전송 트랜잭션 생성
+func (p *Model) BuildCoinTxModel(req *SendRequest, from string) (*UnsignedTx, error) {
+	fromAddress, err := parseAddress("from", from)
+	if err != nil {
+		return nil, err
+	}
+	toAddress, err := parseAddress("address", req.TargetAddress)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ParseAmount(req.Amount, coinDecimals, req.BaseUnit)
+	if err != nil {
+		log.Error("전송량 변환 에러", err.Error())
+		return nil, err
+	}
+
+	unsigned, err := p.buildTx(fromAddress, &toAddress, value, nil)
+	if err != nil {
+		return nil, err
+	}
+	unsigned.Recipient = toAddress.Hex()
+	unsigned.Amount = value.String()
+	return unsigned, nil
+}
+
+// 서비스 지갑 전송과 같은 방식으로 수수료와 gasLimit을 정하고, nonce는 from의 pending nonce 사용
+func (p *Model) buildTx(from common.Address, to *common.Address, value *big.Int, data []byte) (*UnsignedTx, error) {
+	client := p.backend()
+	ctx := context.Background()
+
+	fee, err := p.suggestFee(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit, err := p.estimateGas(ctx, client, from, to, value, data)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		log.Error("PendingNonceAt 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		log.Error("NetworkID 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	tx := fee.newTx(chainID, nonce, to, value, gasLimit, data)
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	unsigned := &UnsignedTx{
+		Type:        tx.Type(),
+		ChainID:     bigString(chainID),
+		Nonce:       nonce,
+		From:        from.Hex(),
+		To:          to.Hex(),
+		Value:       value.String(),
+		Data:        hexutil.Encode(data),
+		GasLimit:    gasLimit,
+		RawTx:       hexutil.Encode(raw),
+		SigningHash: types.LatestSignerForChainID(chainID).Hash(tx).Hex(),
+	}
+	if fee.dynamic() {
+		unsigned.MaxFeePerGas = bigString(fee.gasFeeCap)
+		unsigned.MaxPriorityFeePerGas = bigString(fee.gasTipCap)
+	} else {
+		unsigned.GasPrice = bigString(fee.gasPrice)
+	}
+	return unsigned, nil
+}
+
+// 서명된 트랜잭션 디코딩
+// legacy 트랜잭션과 네트워크 형식(RLP 문자열로 감싼 typed 트랜잭션)은 rlp로, typed 트랜잭션 envelope는 UnmarshalBinary로 디코딩
+func decodeRawTx(rawTx string) (*types.Transaction, error) {
+	data, err := hexutil.Decode(rawTx)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("%w: hex decode", ErrInvalidRawTx)
+	}
+	tx := new(types.Transaction)
+	if data[0] > 0x7f {
+		err = rlp.DecodeBytes(data, tx)
+	} else {
+		err = tx.UnmarshalBinary(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRawTx, err)
+	}
+	return tx, nil
+}
+
+// 클라이언트가 서명한 트랜잭션을 확인한 뒤 전송
+// 체인 ID, 서명한 계정(from이 있으면 from과 비교), 받는 주소를 확인하며 받는 주소는 등록된 토큰의 쓰기 함수 호출 또는 calldata 없는 코인 전송만 허용
+func (p *Model) BroadcastTxModel(rawTx string, from string, wait *WaitOption) (*SendResult, error) {
+	tx, err := decodeRawTx(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	client := p.backend()
+	ctx := context.Background()
+
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		log.Error("NetworkID 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	if tx.ChainId().Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("%w: tx %s, network %s", ErrChainIDMismatch, tx.ChainId(), chainID)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRawTx, err)
+	}
+	expected, err := parseAddress("from", from)
+	if err != nil {
+		return nil, err
+	}
+	if sender != expected {
+		return nil, fmt.Errorf("%w: signed by %s, want %s", ErrSenderMismatch, sender.Hex(), expected.Hex())
+	}
+
+	result := &SendResult{
+		TxHash:   tx.Hash().Hex(),
+		Type:     tx.Type(),
+		Nonce:    tx.Nonce(),
+		From:     sender.Hex(),
+		Amount:   tx.Value().String(),
+		GasLimit: tx.Gas(),
+		ChainID:  bigString(chainID),
+	}
+	if err := p.checkTxTarget(tx, result); err != nil {
+		return nil, err
+	}
+	if tx.Type() == types.LegacyTxType {
+		result.GasPrice = bigString(tx.GasPrice())
+	} else {
+		result.MaxFeePerGas = bigString(tx.GasFeeCap())
+		result.MaxPriorityFeePerGas = bigString(tx.GasTipCap())
+	}
+
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		log.Error("트랜잭션 전송 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	log.InfoFields("signed tx broadcast", result.logFields()...)
+
+	// wait 옵션이 있으면 영수증을 받을 때까지 대기
+	if wait != nil {
+		receipt, err := p.waitReceipt(client, tx, wait)
+		result.Receipt = receipt
+		if err != nil {
+			log.Error("영수증 대기 에러", err.Error())
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// 받는 주소 확인, 토큰 호출이면 calldata를 디코딩해 result에 호출 정보를 채움
+func (p *Model) checkTxTarget(tx *types.Transaction, result *SendResult) error {
+	if tx.To() == nil {
+		return fmt.Errorf("%w: contract creation", ErrInvalidTxTarget)
+	}
+	to := *tx.To()
+	if !p.tokens.has(to.Hex()) {
+		if len(tx.Data()) > 0 {
+			return fmt.Errorf("%w: calldata to unregistered address %s", ErrInvalidTxTarget, to.Hex())
+		}
+		result.To = to.Hex()
+		return nil
+	}
+
+	parsed, err := cont.ContractsMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	if len(tx.Data()) < 4 || tx.Value().Sign() != 0 {
+		return fmt.Errorf("%w: token %s requires a method call without value", ErrInvalidTxTarget, to.Hex())
+	}
+	method, err := parsed.MethodById(tx.Data()[:4])
+	if err != nil || method.IsConstant() {
+		return fmt.Errorf("%w: unknown token method", ErrInvalidTxTarget)
+	}
+	args := make(map[string]interface{})
+	if err := method.Inputs.UnpackIntoMap(args, tx.Data()[4:]); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidTxTarget, err)
+	}
+	result.Method = method.Name
+	result.Token = to.Hex()
+	if v, ok := args["amount"].(*big.Int); ok {
+		result.Amount = v.String()
+	}
+	if v, ok := args["sender"].(common.Address); ok {
+		result.Sender = v.Hex()
+	}
+	for _, name := range []string{"recipient", "spender"} {
+		if v, ok := args[name].(common.Address); ok {
+			result.To = v.Hex()
+		}
+	}
+	return nil
+}
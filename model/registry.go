@@ -0,0 +1,323 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// 레지스트리에 없는 토큰을 지정한 경우 반환
+	ErrUnknownToken = errors.New("unknown token")
+	// 이미 같은 주소의 토큰이 등록된 경우 반환
+	ErrTokenExists = errors.New("token already registered")
+	// 같은 심볼의 토큰이 여러 개 등록되어 심볼로 구분할 수 없는 경우 반환
+	ErrAmbiguousToken = errors.New("ambiguous token symbol")
+	// 기본 토큰을 레지스트리에서 제거하려는 경우 반환
+	ErrDefaultToken = errors.New("default token cannot be removed")
+)
+
+// 레지스트리에 등록된 토큰 정보, name, symbol, decimals는 등록 시점에 체인에서 조회해 캐시
+type TokenInfo struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+	Default  bool   `json:"default"`
+}
+
+// 주소와 심볼로 토큰을 찾는 레지스트리
+// 심볼은 대소문자를 구분하지 않으며, token 값이 비어있으면 기본 토큰 사용
+// 같은 심볼의 토큰이 여러 개면 심볼로는 찾을 수 없고 주소로 지정해야 함
+type tokenRegistry struct {
+	mu       sync.RWMutex
+	byAddr   map[common.Address]*TokenInfo
+	bySymbol map[string][]*TokenInfo
+	defaults common.Address
+
+	path  string // 실행 중 변경을 기록하는 파일, 비어있으면 메모리에만 보관
+	state registryState
+}
+
+// 실행 중에 등록, 제거한 토큰 주소, 재시작하면 config와 statePath로 등록한 뒤 반영
+// Removed는 config나 statePath로 등록된 토큰을 제거한 경우에도 기록
+type registryState struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+func newTokenRegistry() *tokenRegistry {
+	return &tokenRegistry{
+		byAddr:   make(map[common.Address]*TokenInfo),
+		bySymbol: make(map[string][]*TokenInfo),
+	}
+}
+
+func (r *tokenRegistry) add(info *TokenInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addr := common.HexToAddress(info.Address)
+	if _, ok := r.byAddr[addr]; ok {
+		return fmt.Errorf("%w: %s", ErrTokenExists, info.Address)
+	}
+	symbol := strings.ToUpper(info.Symbol)
+	r.byAddr[addr] = info
+	r.bySymbol[symbol] = append(r.bySymbol[symbol], info)
+	if info.Default {
+		r.defaults = addr
+	}
+	return nil
+}
+
+func (r *tokenRegistry) remove(token string) (*TokenInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := r.lookup(token)
+	if err != nil {
+		return nil, err
+	}
+	if info.Default {
+		return nil, fmt.Errorf("%w: %s", ErrDefaultToken, info.Symbol)
+	}
+	delete(r.byAddr, common.HexToAddress(info.Address))
+
+	symbol := strings.ToUpper(info.Symbol)
+	remaining := r.bySymbol[symbol][:0]
+	for _, other := range r.bySymbol[symbol] {
+		if other != info {
+			remaining = append(remaining, other)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(r.bySymbol, symbol)
+	} else {
+		r.bySymbol[symbol] = remaining
+	}
+	return info, nil
+}
+
+func (r *tokenRegistry) hasDefault() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.byAddr[r.defaults]
+	return ok && info.Default
+}
+
+func (r *tokenRegistry) has(address string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.byAddr[common.HexToAddress(address)]
+	return ok
+}
+
+// token은 주소 또는 심볼, 비어있으면 기본 토큰
+func (r *tokenRegistry) get(token string) (*TokenInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, err := r.lookup(token)
+	if err != nil {
+		return nil, err
+	}
+	copied := *info
+	return &copied, nil
+}
+
+// 잠금을 잡은 상태에서 호출
+func (r *tokenRegistry) lookup(token string) (*TokenInfo, error) {
+	if token == "" {
+		if info, ok := r.byAddr[r.defaults]; ok && info.Default {
+			return info, nil
+		}
+		return nil, fmt.Errorf("%w: 기본 토큰이 설정되지 않았습니다", ErrUnknownToken)
+	}
+	if common.IsHexAddress(token) {
+		if info, ok := r.byAddr[common.HexToAddress(token)]; ok {
+			return info, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnknownToken, token)
+	}
+	switch infos := r.bySymbol[strings.ToUpper(token)]; len(infos) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownToken, token)
+	case 1:
+		return infos[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %s, 주소로 지정해야 합니다", ErrAmbiguousToken, token)
+	}
+}
+
+// 심볼 순으로 정렬한 등록 토큰 목록
+func (r *tokenRegistry) list() []TokenInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]TokenInfo, 0, len(r.byAddr))
+	for _, info := range r.byAddr {
+		tokens = append(tokens, *info)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].Symbol != tokens[j].Symbol {
+			return tokens[i].Symbol < tokens[j].Symbol
+		}
+		return tokens[i].Address < tokens[j].Address
+	})
+	return tokens
+}
+
+// 실행 중 등록(added가 true) 또는 제거를 path 파일에 기록, 저장하지 못하면 기록을 되돌림
+func (r *tokenRegistry) record(address string, added bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path == "" {
+		return nil
+	}
+	prev := r.state
+	r.state = registryState{Added: withoutAddress(prev.Added, address), Removed: withoutAddress(prev.Removed, address)}
+	if added {
+		r.state.Added = append(r.state.Added, address)
+	} else {
+		r.state.Removed = append(r.state.Removed, address)
+	}
+	if err := writeJSON(r.path, &r.state); err != nil {
+		r.state = prev
+		return err
+	}
+	return nil
+}
+
+func withoutAddress(addresses []string, address string) []string {
+	kept := []string{}
+	for _, a := range addresses {
+		if !strings.EqualFold(a, address) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// registry path 파일에 기록된 실행 중 변경을 반영
+// 등록한 토큰은 체인에서 다시 조회해 추가하고, 제거한 토큰은 config나 statePath로 등록되었어도 제거
+func (p *Model) loadRegistryState(ctx context.Context) error {
+	r := p.tokens
+	if r.path == "" {
+		return nil
+	}
+	if err := readJSON(r.path, &r.state); err != nil {
+		return err
+	}
+	for _, address := range r.state.Added {
+		if r.has(address) {
+			continue
+		}
+		info, err := p.fetchTokenInfo(ctx, address)
+		if err != nil {
+			return err
+		}
+		if err := r.add(info); err != nil {
+			return err
+		}
+	}
+	for _, address := range r.state.Removed {
+		if !r.has(address) {
+			continue
+		}
+		// 기본 토큰은 제거하지 않음
+		if _, err := r.remove(address); err != nil && !errors.Is(err, ErrDefaultToken) {
+			return err
+		}
+	}
+	return nil
+}
+
+// 체인에서 name, symbol, decimals를 조회해 토큰 정보 생성
+func (p *Model) fetchTokenInfo(ctx context.Context, address string) (*TokenInfo, error) {
+	addr, err := parseAddress("token", address)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := cont.NewContractsCaller(addr, p.backend())
+	if err != nil {
+		log.Error("NewContractsCaller 에러", err.Error())
+		return nil, err
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	name, err := instance.Name(opts)
+	if err != nil {
+		log.Error("Token Name 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	symbol, err := instance.Symbol(opts)
+	if err != nil {
+		log.Error("Symbol 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	decimals, err := instance.Decimals(opts)
+	if err != nil {
+		log.Error("Decimals 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	return &TokenInfo{Address: addr.Hex(), Name: name, Symbol: symbol, Decimals: decimals}, nil
+}
+
+// 요청의 token 값으로 토큰 정보 조회
+func (p *Model) resolveToken(token string) (*TokenInfo, error) {
+	return p.tokens.get(token)
+}
+
+func (p *Model) ListTokensModel() []TokenInfo {
+	return p.tokens.list()
+}
+
+func (p *Model) SearchTokenInfoModel(token string) (*TokenInfo, error) {
+	return p.resolveToken(token)
+}
+
+// 토큰 컨트랙트를 조회해 레지스트리에 추가, registry path가 있으면 재시작해도 유지
+func (p *Model) AddTokenModel(address string) (*TokenInfo, error) {
+	info, err := p.fetchTokenInfo(context.Background(), address)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.tokens.add(info); err != nil {
+		return nil, err
+	}
+	if err := p.tokens.record(info.Address, true); err != nil {
+		p.tokens.remove(info.Address)
+		return nil, err
+	}
+	log.Info("토큰 등록", info.Symbol+" "+info.Address)
+	return info, nil
+}
+
+// 주소 또는 심볼로 레지스트리에서 제거, 기본 토큰은 제거할 수 없음
+// registry path가 있으면 config나 statePath로 등록된 토큰도 재시작 후 다시 등록되지 않음
+func (p *Model) RemoveTokenModel(token string) (*TokenInfo, error) {
+	info, err := p.tokens.remove(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.tokens.record(info.Address, false); err != nil {
+		p.tokens.add(info)
+		return nil, err
+	}
+	log.Info("토큰 제거", info.Symbol+" "+info.Address)
+	return info, nil
+}
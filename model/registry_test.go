@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTokenRegistryLookup(t *testing.T) {
+	r := newTokenRegistry()
+	ykk := &TokenInfo{Address: "0x0000000000000000000000000000000000000001", Symbol: "YKK", Decimals: 18, Default: true}
+	usd := &TokenInfo{Address: "0x0000000000000000000000000000000000000002", Symbol: "USD", Decimals: 6}
+	for _, info := range []*TokenInfo{ykk, usd} {
+		if err := r.add(info); err != nil {
+			t.Fatalf("add Error: %s", err)
+		}
+	}
+
+	for token, want := range map[string]string{
+		"":    ykk.Address,
+		"ykk": ykk.Address,
+		"USD": usd.Address,
+		"0x0000000000000000000000000000000000000002": usd.Address,
+	} {
+		info, err := r.get(token)
+		if err != nil || info.Address != want {
+			t.Errorf("get(%q) = %v, %v, want %s", token, info, err, want)
+		}
+	}
+	if _, err := r.get("ABC"); !errors.Is(err, ErrUnknownToken) {
+		t.Errorf("get unknown Error = %v", err)
+	}
+	if err := r.add(&TokenInfo{Address: usd.Address, Symbol: "USD"}); !errors.Is(err, ErrTokenExists) {
+		t.Errorf("add duplicate Error = %v", err)
+	}
+}
+
+func TestTokenRegistryAmbiguousSymbol(t *testing.T) {
+	r := newTokenRegistry()
+	first := &TokenInfo{Address: "0x0000000000000000000000000000000000000001", Symbol: "YKK", Default: true}
+	second := &TokenInfo{Address: "0x0000000000000000000000000000000000000002", Symbol: "YKK"}
+	r.add(first)
+	r.add(second)
+
+	if _, err := r.get("YKK"); !errors.Is(err, ErrAmbiguousToken) {
+		t.Errorf("get ambiguous Error = %v", err)
+	}
+	if _, err := r.remove(first.Address); !errors.Is(err, ErrDefaultToken) {
+		t.Errorf("remove default Error = %v", err)
+	}
+	if _, err := r.remove(second.Address); err != nil {
+		t.Fatalf("remove Error: %s", err)
+	}
+	if info, err := r.get("YKK"); err != nil || info.Address != first.Address {
+		t.Errorf("get after remove = %v, %v", info, err)
+	}
+	if len(r.list()) != 1 {
+		t.Errorf("list = %v", r.list())
+	}
+}
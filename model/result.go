@@ -0,0 +1,74 @@
+package model
+
+import (
+	"math/big"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 전송 요청 정보
+type SendRequest struct {
+	Token         string // 토큰 주소 또는 심볼, 비어있으면 기본 토큰. 코인 전송에는 사용하지 않음
+	TargetAddress string
+	Amount        string
+	BaseUnit      bool        // true면 Amount를 최소 단위 정수로 해석
+	Wait          *WaitOption // nil이 아니면 영수증을 받을 때까지 대기
+	APIKey        string      // 요청한 API key id, 전송 한도 확인에 사용. 인증을 사용하지 않으면 비어있음
+}
+
+// 영수증 대기 옵션, 0 값은 config의 기본값 사용
+type WaitOption struct {
+	Confirmations uint64
+	Timeout       time.Duration
+}
+
+// 전송 결과, 송금 내역 대사를 위해 트랜잭션 정보를 그대로 반환
+// legacy 트랜잭션은 gasPrice, dynamic fee 트랜잭션은 maxFeePerGas, maxPriorityFeePerGas를 채움
+type SendResult struct {
+	TxHash               string `json:"txHash"`
+	Type                 uint8  `json:"type"`
+	Method               string `json:"method,omitempty"`
+	Nonce                uint64 `json:"nonce"`
+	From                 string `json:"from"`
+	Sender               string `json:"sender,omitempty"` // transferFrom으로 토큰이 빠져나가는 주소
+	To                   string `json:"to,omitempty"`
+	Token                string `json:"token,omitempty"`
+	Amount               string `json:"amount"`
+	GasPrice             string `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"`
+	GasLimit             uint64 `json:"gasLimit"`
+	ChainID              string `json:"chainId"`
+
+	// wait 옵션 사용시 채워지는 영수증 정보
+	Receipt *TxStatus `json:"receipt,omitempty"`
+}
+
+// big.Int는 json 숫자 정밀도 손실을 막기 위해 문자열로 변환
+func bigString(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+// zap 로거에 남길 구조화 필드
+func (r *SendResult) logFields() []zap.Field {
+	return []zap.Field{
+		zap.String("txHash", r.TxHash),
+		zap.Uint8("type", r.Type),
+		zap.String("method", r.Method),
+		zap.Uint64("nonce", r.Nonce),
+		zap.String("from", r.From),
+		zap.String("sender", r.Sender),
+		zap.String("to", r.To),
+		zap.String("token", r.Token),
+		zap.String("amount", r.Amount),
+		zap.String("gasPrice", r.GasPrice),
+		zap.String("maxFeePerGas", r.MaxFeePerGas),
+		zap.String("maxPriorityFeePerGas", r.MaxPriorityFeePerGas),
+		zap.Uint64("gasLimit", r.GasLimit),
+		zap.String("chainId", r.ChainID),
+	}
+}
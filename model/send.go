@@ -0,0 +1,131 @@
+package model
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 전송에 사용할 config의 keystore 개인키
+func (p *Model) signingKey() (*ecdsa.PrivateKey, error) {
+	privateKey, err := crypto.HexToECDSA(p.privateKey)
+	if err != nil {
+		log.Error("HexToECDSA 에러", err.Error())
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+// nonce를 발급받아 트랜잭션을 서명하고 전송
+// newTx는 발급받은 nonce로 서명 전 트랜잭션을 생성하며, 생성, 서명에 실패하거나 노드가 거부한 nonce는 반환함
+// 연결 에러나 timeout처럼 노드가 받았는지 알 수 없으면 nonce를 반환하지 않고 체인 기준으로 재동기화
+// 노드가 nonce 불일치로 거부하면 체인 기준으로 재동기화한 뒤 한 번 더 시도
+func (p *Model) signAndSend(ctx context.Context, client ChainBackend, key *ecdsa.PrivateKey, chainID *big.Int, newTx func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	for attempt := 0; ; attempt++ {
+		nonce, err := p.nonces.acquire(ctx, client, from)
+		if err != nil {
+			log.Error("nonce 발급 에러", err.Error())
+			p.checkConn(err)
+			return nil, err
+		}
+
+		tx, err := newTx(nonce)
+		if err != nil {
+			log.Error("트랜잭션 생성 에러", err.Error())
+			p.nonces.release(from, nonce)
+			return nil, err
+		}
+
+		// 트랜잭션 서명, legacy와 dynamic fee 트랜잭션 모두 체인 ID 기준 최신 signer 사용
+		signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+		if err != nil {
+			log.Error("트랜잭션 서명 에러", err.Error())
+			p.nonces.release(from, nonce)
+			return nil, err
+		}
+
+		// 트랜잭션 전송
+		err = client.SendTransaction(ctx, signedTx)
+		if err == nil {
+			return signedTx, nil
+		}
+		log.Error("트랜잭션 전송 에러", err.Error())
+		p.checkConn(err)
+
+		if isTxRejected(err) && !isNonceError(err) {
+			p.nonces.release(from, nonce)
+			return nil, err
+		}
+		// nonce 불일치 또는 노드가 받았는지 알 수 없는 경우 체인의 pending nonce로 다시 동기화
+		// 다른 요청이 전송 중인 nonce를 다시 발급하지 않도록 nonce too high로 거부된 경우에만 되돌림
+		if syncErr := p.nonces.resync(ctx, client, from, isTxRejected(err) && isNonceTooHigh(err)); syncErr != nil {
+			log.Error("nonce 재동기화 에러", syncErr.Error())
+			return nil, err
+		}
+		if attempt > 0 || !isNonceError(err) {
+			return nil, err
+		}
+	}
+}
+
+// 생성된 컨트랙트 바인딩 함수로 트랜잭션을 만들어 전송
+// call은 Transfer, Approve 같은 바인딩 함수를 opts로 호출하며, 바인딩에는 NoSend로 트랜잭션 생성까지만 맡김
+// 수수료, gasLimit 예측, nonce 발급과 전송은 코인 전송과 같은 경로로 처리
+// contract가 nil이면 DeployContracts 같은 배포 트랜잭션
+func (p *Model) transactContract(ctx context.Context, client ChainBackend, key *ecdsa.PrivateKey, chainID *big.Int, contract *common.Address, call func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *txFee, error) {
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	// 수수료 설정. dynamic fee 또는 추천되는 gasPrice를 가져옴
+	fee, err := p.suggestFee(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		log.Error("NewKeyedTransactorWithChainID 에러", err.Error())
+		return nil, nil, err
+	}
+	opts.Context = ctx
+	opts.NoSend = true
+	if fee.dynamic() {
+		opts.GasFeeCap, opts.GasTipCap = fee.gasFeeCap, fee.gasTipCap
+	} else {
+		opts.GasPrice = fee.gasPrice
+	}
+
+	// gasLimit 예측에 쓸 calldata를 얻기 위해 임시 nonce, gasLimit으로 먼저 생성
+	opts.Nonce, opts.GasLimit = big.NewInt(0), 1
+	draft, err := call(opts)
+	if err != nil {
+		log.Error("컨트랙트 트랜잭션 생성 에러", err.Error())
+		p.checkConn(err)
+		return nil, nil, err
+	}
+
+	// 실제 호출 메시지로 gasLimit 예측
+	gasLimit, err := p.estimateGas(ctx, client, from, contract, draft.Value(), draft.Data())
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.GasLimit = gasLimit
+
+	// 트랜잭션 생성, 서명 및 전송
+	signedTx, err := p.signAndSend(ctx, client, key, chainID, func(nonce uint64) (*types.Transaction, error) {
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		return call(opts)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return signedTx, fee, nil
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	conf "go-contract/config"
+)
+
+// 서명 timestamp 허용 오차 기본값
+const defaultMaxSkew = 300 * time.Second
+
+// nonce 최대 길이, 캐시 메모리를 제한하기 위해 사용
+const maxNonceLength = 64
+
+// 요청 서명 헤더
+// 서명은 "method\npath\nsha256(body)\ntimestamp\nnonce"의 HMAC-SHA256을 "sha256=hex" 형식으로 보냄
+const (
+	RequestSignatureHeader = "X-Signature"
+	RequestTimestampHeader = "X-Timestamp"
+	RequestNonceHeader     = "X-Nonce"
+)
+
+var (
+	// 서명 헤더가 없거나 서명이 맞지 않는 경우 반환
+	ErrInvalidSignature = errors.New("invalid request signature")
+	// timestamp가 허용 오차를 벗어난 경우 반환
+	ErrStaleTimestamp = errors.New("stale request timestamp")
+	// 이미 사용한 nonce인 경우 반환
+	ErrReplayedNonce = errors.New("replayed request nonce")
+)
+
+// 요청 서명, path는 쿼리를 포함한 경로(/v1/token/?wait=true)
+func SignRequest(secret string, method string, path string, body []byte, timestamp string, nonce string) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.ToUpper(method) + "\n" + path + "\n" + hex.EncodeToString(bodyHash[:]) + "\n" + timestamp + "\n" + nonce))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// 서명 확인에 사용한 nonce, timestamp 허용 범위를 벗어나면 다시 보낼 수 없으므로 그때까지만 보관
+type nonceCache struct {
+	mu       sync.Mutex
+	expires  map[string]time.Time
+	prunedAt time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{expires: make(map[string]time.Time)}
+}
+
+// 처음 사용한 nonce면 expire까지 기록하고 true 반환
+func (n *nonceCache) use(nonce string, expire time.Time, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if exp, ok := n.expires[nonce]; ok && now.Before(exp) {
+		return false
+	}
+	n.expires[nonce] = expire
+	// 만료된 nonce는 최대 1초에 한 번 정리
+	if now.Sub(n.prunedAt) >= time.Second {
+		for k, exp := range n.expires {
+			if !now.Before(exp) {
+				delete(n.expires, k)
+			}
+		}
+		n.prunedAt = now
+	}
+	return true
+}
+
+// 서명된 요청 검증 설정
+type requestVerifier struct {
+	enabled bool
+	secret  string
+	maxSkew time.Duration
+	nonces  *nonceCache
+}
+
+func newRequestVerifier(cfg *conf.Config) (*requestVerifier, error) {
+	v := &requestVerifier{
+		enabled: cfg.Auth.SignedRequests,
+		secret:  cfg.Auth.SigningSecret,
+		maxSkew: time.Duration(cfg.Auth.MaxSkew) * time.Second,
+		nonces:  newNonceCache(),
+	}
+	if v.maxSkew <= 0 {
+		v.maxSkew = defaultMaxSkew
+	}
+	if v.enabled && v.secret == "" {
+		return nil, errors.New("auth signingSecret is empty")
+	}
+	return v, nil
+}
+
+func (v *requestVerifier) verify(method string, path string, body []byte, timestamp string, nonce string, signature string, now time.Time) error {
+	if timestamp == "" || nonce == "" || signature == "" {
+		return fmt.Errorf("%w: missing %s, %s or %s header", ErrInvalidSignature, RequestTimestampHeader, RequestNonceHeader, RequestSignatureHeader)
+	}
+	if len(nonce) > maxNonceLength {
+		return fmt.Errorf("%w: nonce longer than %d", ErrInvalidSignature, maxNonceLength)
+	}
+	expected := SignRequest(v.secret, method, path, body, timestamp, nonce)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	// 서명이 맞는 요청만 timestamp와 nonce 확인
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrStaleTimestamp, timestamp)
+	}
+	signedAt := time.Unix(sec, 0)
+	if skew := now.Sub(signedAt); skew > v.maxSkew || skew < -v.maxSkew {
+		return fmt.Errorf("%w: %s", ErrStaleTimestamp, timestamp)
+	}
+	if !v.nonces.use(nonce, signedAt.Add(v.maxSkew), now) {
+		return fmt.Errorf("%w: %s", ErrReplayedNonce, nonce)
+	}
+	return nil
+}
+
+// 요청 서명 사용 여부
+func (p *Model) SignedRequestsEnabled() bool {
+	return p.verifier.enabled
+}
+
+// 요청 서명, timestamp, nonce 확인
+func (p *Model) VerifyRequestModel(method string, path string, body []byte, timestamp string, nonce string, signature string) error {
+	return p.verifier.verify(method, path, body, timestamp, nonce, signature, time.Now())
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	conf "go-contract/config"
+)
+
+func TestRequestVerifier(t *testing.T) {
+	cfg := new(conf.Config)
+	cfg.Auth.SignedRequests = true
+	cfg.Auth.SigningSecret = "secret"
+	cfg.Auth.MaxSkew = 60
+	v, err := newRequestVerifier(cfg)
+	if err != nil {
+		t.Fatalf("newRequestVerifier Error: %s", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"rawTx":"0x01"}`)
+	sig := SignRequest("secret", "POST", "/v1/tx/broadcast", body, ts, "n1")
+
+	if err := v.verify("POST", "/v1/tx/broadcast", body, ts, "n1", sig, now); err != nil {
+		t.Fatalf("verify Error: %s", err)
+	}
+	// 같은 nonce는 허용 범위 안에서 다시 사용할 수 없음
+	if err := v.verify("POST", "/v1/tx/broadcast", body, ts, "n1", sig, now.Add(30*time.Second)); !errors.Is(err, ErrReplayedNonce) {
+		t.Errorf("replay err = %v", err)
+	}
+	// 허용 범위를 벗어나면 timestamp로 거부
+	if err := v.verify("POST", "/v1/tx/broadcast", body, ts, "n1", sig, now.Add(61*time.Second)); !errors.Is(err, ErrStaleTimestamp) {
+		t.Errorf("stale err = %v", err)
+	}
+	// method, path, body, secret 중 하나라도 다르면 서명 불일치
+	for name, s := range map[string]string{
+		"method": SignRequest("secret", "DELETE", "/v1/tx/broadcast", body, ts, "n2"),
+		"path":   SignRequest("secret", "POST", "/v1/token/", body, ts, "n2"),
+		"body":   SignRequest("secret", "POST", "/v1/tx/broadcast", []byte("{}"), ts, "n2"),
+		"secret": SignRequest("other", "POST", "/v1/tx/broadcast", body, ts, "n2"),
+	} {
+		if err := v.verify("POST", "/v1/tx/broadcast", body, ts, "n2", s, now); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s err = %v", name, err)
+		}
+	}
+
+	// 만료된 nonce는 정리됨
+	v.nonces.use("n3", now.Add(time.Second), now)
+	v.nonces.use("n4", now.Add(time.Hour), now.Add(2*time.Second))
+	if _, ok := v.nonces.expires["n3"]; ok || len(v.nonces.expires) != 2 {
+		t.Errorf("nonces = %v", v.nonces.expires)
+	}
+
+	cfg.Auth.SigningSecret = ""
+	if _, err := newRequestVerifier(cfg); err == nil {
+		t.Errorf("empty secret accepted")
+	}
+}
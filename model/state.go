@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// deploy로 배포한 컨트랙트 기록
+type Deployment struct {
+	Address     string    `json:"address"`
+	TxHash      string    `json:"txHash"`
+	Deployer    string    `json:"deployer"`
+	BlockNumber string    `json:"blockNumber"`
+	ChainID     string    `json:"chainId"`
+	DeployedAt  time.Time `json:"deployedAt"`
+}
+
+// statePath 파일 내용, 배포 순서대로 기록
+type deployState struct {
+	Deployments []Deployment `json:"deployments"`
+}
+
+// 파일이 없으면 빈 상태 반환
+func readState(path string) (*deployState, error) {
+	state := new(deployState)
+	if err := readJSON(path, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// JSON 파일을 v로 읽음, 파일이 없으면 v를 그대로 둠
+func readJSON(path string, v interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// 쓰는 도중 종료되어도 기존 파일이 깨지지 않도록 임시 파일에 쓴 뒤 교체
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// 배포 기록을 statePath 파일에 추가, statePath가 비어있으면 기록하지 않음
+func (p *Model) recordDeployment(d Deployment) error {
+	if p.statePath == "" {
+		return nil
+	}
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+
+	state, err := readState(p.statePath)
+	if err != nil {
+		return err
+	}
+	state.Deployments = append(state.Deployments, d)
+	return writeJSON(p.statePath, state)
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDeployState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := readState(path)
+	if err != nil || len(state.Deployments) != 0 {
+		t.Fatalf("readState missing file = %+v, %v", state, err)
+	}
+
+	p := &Model{statePath: path}
+	for _, addr := range []string{"0x01", "0x02"} {
+		if err := p.recordDeployment(Deployment{Address: addr, ChainID: "1337"}); err != nil {
+			t.Fatalf("recordDeployment Error: %s", err)
+		}
+	}
+	state, err = readState(path)
+	if err != nil || len(state.Deployments) != 2 || state.Deployments[1].Address != "0x02" {
+		t.Errorf("readState = %+v, %v", state, err)
+	}
+}
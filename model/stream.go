@@ -0,0 +1,436 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 스트리밍 이벤트 종류
+const (
+	EventTransfer = "transfer"
+	EventApproval = "approval"
+)
+
+const (
+	// 구독이 끊겼을 때 다시 구독하기 전 대기 시간
+	minResubscribeDelay = time.Second
+	maxResubscribeDelay = 30 * time.Second
+	// 중복 전달 확인을 위해 기억하는 최근 블록 수, reorg로 다시 오는 로그도 이 범위 안에서 구분
+	seenBlockWindow = 64
+)
+
+// wsUrl 없이 http rpc로 연결해 이벤트를 구독할 수 없는 경우 반환
+var ErrSubscriptionUnsupported = errors.New("event subscription requires a websocket rpc")
+
+// 구독 중 클라이언트에 전달하는 토큰 이벤트
+// transfer는 from, to, approval은 owner, spender를 채우며 reorg로 취소된 로그는 removed가 true
+type TokenEvent struct {
+	Event       string `json:"event"`
+	Token       string `json:"token"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Spender     string `json:"spender,omitempty"`
+	Value       string `json:"value"`
+	Removed     bool   `json:"removed,omitempty"`
+}
+
+// 이벤트 위치, SSE의 id와 resume cursor로 사용
+func (e TokenEvent) ID() string {
+	return logPosition{block: e.BlockNumber, index: e.LogIndex}.String()
+}
+
+// 이벤트 구독 조건
+// Address가 있으면 해당 주소가 from, to, owner, spender 중 하나인 이벤트만 전달
+// FromBlock 또는 Cursor가 있으면 그 위치부터 지난 이벤트를 먼저 전달한 뒤 실시간 이벤트를 전달
+type EventQuery struct {
+	Token     string
+	Address   string
+	FromBlock *uint64
+	Cursor    string
+}
+
+// 이미 전달한 이벤트 기록, 재구독시 지난 이벤트를 다시 조회해도 중복 전달하지 않음
+// after가 있으면 그 위치 이후의 이벤트만 전달
+type eventTracker struct {
+	resume uint64
+	after  *logPosition
+	seen   map[eventKey]bool
+	active bool
+}
+
+type eventKey struct {
+	pos     logPosition
+	event   string
+	removed bool
+}
+
+func newEventTracker(after *logPosition) *eventTracker {
+	return &eventTracker{after: after, seen: make(map[eventKey]bool)}
+}
+
+// 처음 보는 이벤트면 기록하고 true 반환, 재구독시 마지막으로 전달한 블록부터 다시 조회하도록 resume 갱신
+func (t *eventTracker) admit(ev TokenEvent) bool {
+	pos := logPosition{block: ev.BlockNumber, index: ev.LogIndex}
+	if t.after != nil && !ev.Removed && (t.after.before(pos.block, pos.index) || *t.after == pos) {
+		return false
+	}
+	if t.active && pos.block+seenBlockWindow < t.resume {
+		return false
+	}
+	key := eventKey{pos: pos, event: ev.Event, removed: ev.Removed}
+	if t.seen[key] {
+		return false
+	}
+	t.seen[key] = true
+	t.active = true
+	if pos.block > t.resume {
+		t.resume = pos.block
+		for k := range t.seen {
+			if k.pos.block+seenBlockWindow < t.resume {
+				delete(t.seen, k)
+			}
+		}
+	}
+	return true
+}
+
+// 조건을 확인한 이벤트 구독, Run으로 이벤트를 전달
+type EventStream struct {
+	md       *Model
+	token    common.Address
+	address  *common.Address
+	tracker  *eventTracker
+	backfill bool
+}
+
+// 이벤트 구독 조건을 확인하고 EventStream 생성
+// 응답을 시작하기 전에 잘못된 조건을 에러로 반환하기 위해 구독은 Run에서 시작
+func (p *Model) StreamTokenEventsModel(q *EventQuery) (*EventStream, error) {
+	if !p.canSubscribe() {
+		return nil, fmt.Errorf("%w: contract.wsUrl is not set and netUrl is %s", ErrSubscriptionUnsupported, p.netUrl)
+	}
+	s := &EventStream{md: p, tracker: newEventTracker(nil)}
+	if q.Address != "" {
+		addr, err := parseAddress("address", q.Address)
+		if err != nil {
+			return nil, err
+		}
+		s.address = &addr
+	}
+	token, err := p.resolveToken(q.Token)
+	if err != nil {
+		return nil, err
+	}
+	s.token = common.HexToAddress(token.Address)
+
+	// cursor 또는 from_block이 있으면 그 블록부터 지난 이벤트를 먼저 전달
+	if q.Cursor != "" {
+		pos, err := parseCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		s.tracker.after, s.tracker.resume, s.backfill = &pos, pos.block, true
+	} else if q.FromBlock != nil {
+		s.tracker.resume, s.backfill = *q.FromBlock, true
+	}
+	return s, nil
+}
+
+// Transfer, Approval 이벤트를 WatchTransfer, WatchApproval로 구독해 out으로 전달
+// 구독이 끊기면 마지막으로 전달한 블록부터 지난 이벤트를 다시 조회한 뒤 재구독하며, ctx가 끝나거나 Model을 Close할 때까지 반환하지 않음
+// 반환할 때 out을 닫으므로 받는 쪽은 out이 닫히면 스트림을 끝냄
+func (s *EventStream) Run(ctx context.Context, out chan<- TokenEvent) {
+	defer close(out)
+	p := s.md
+	delay := minResubscribeDelay
+	for {
+		client, err := p.watchBackend()
+		if p.isClosed() {
+			return
+		}
+		if err == nil {
+			started := time.Now()
+			err = p.watchOnce(ctx, client, s.token, s.address, s.tracker, s.backfill, out)
+			if ctx.Err() != nil || p.isClosed() {
+				return
+			}
+			log.Error("이벤트 구독 종료", err.Error())
+			p.dropWatchBackend(client, err)
+			// 한동안 유지된 구독이 끊긴 경우 바로 다시 구독
+			if time.Since(started) > maxResubscribeDelay {
+				delay = minResubscribeDelay
+			}
+		} else {
+			log.Error("이벤트 구독 연결 에러", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > maxResubscribeDelay {
+			delay = maxResubscribeDelay
+		}
+		// 재구독시 마지막으로 전달한 블록부터 다시 조회
+		s.backfill = true
+	}
+}
+
+// 한 번의 구독, 구독이 끊기면 에러 반환
+// backfill이면 tracker.resume 블록부터 지난 이벤트를 먼저 전달하고, 아니면 구독 시점 이후의 이벤트만 전달
+func (p *Model) watchOnce(ctx context.Context, client ChainBackend, token common.Address, address *common.Address, tracker *eventTracker, backfill bool, out chan<- TokenEvent) error {
+	filterer, err := cont.NewContractsFilterer(token, client)
+	if err != nil {
+		return err
+	}
+
+	// 지난 이벤트 조회와 구독 사이의 이벤트를 놓치지 않도록 먼저 구독
+	transfers := make(chan *cont.ContractsTransfer, 64)
+	approvals := make(chan *cont.ContractsApproval, 64)
+	var subs []event.Subscription
+	defer func() {
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+	}()
+	opts := &bind.WatchOpts{Context: ctx}
+	for _, rule := range eventRules(address) {
+		sub, err := filterer.WatchTransfer(opts, transfers, rule[0], rule[1])
+		if err != nil {
+			return err
+		}
+		subs = append(subs, sub)
+		sub, err = filterer.WatchApproval(opts, approvals, rule[0], rule[1])
+		if err != nil {
+			return err
+		}
+		subs = append(subs, sub)
+	}
+	errc := make(chan error, len(subs))
+	for _, sub := range subs {
+		go func(sub event.Subscription) {
+			if err, ok := <-sub.Err(); ok && err != nil {
+				errc <- err
+			} else {
+				errc <- errors.New("subscription closed")
+			}
+		}(sub)
+	}
+
+	send := func(ev TokenEvent) error {
+		if !tracker.admit(ev) {
+			return nil
+		}
+		select {
+		case out <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if backfill {
+		events, err := p.pastEvents(ctx, client, filterer, address, tracker.resume)
+		if err != nil {
+			return err
+		}
+		for _, ev := range events {
+			if err := send(ev); err != nil {
+				return err
+			}
+		}
+	} else {
+		// 구독 전에 끊기면 구독 시점 다음 블록부터 다시 조회
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		tracker.resume = head.Number.Uint64() + 1
+	}
+	log.Info("이벤트 구독 시작", token.Hex())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errc:
+			return err
+		case ev := <-transfers:
+			if err := send(transferEvent(ev)); err != nil {
+				return err
+			}
+		case ev := <-approvals:
+			if err := send(approvalEvent(ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// 주소 조건별 indexed topic 규칙, 주소가 없으면 전체 이벤트
+// Transfer(from, to), Approval(owner, spender) 모두 주소가 첫 번째 또는 두 번째 topic인 경우를 각각 구독
+func eventRules(address *common.Address) [][2][]common.Address {
+	if address == nil {
+		return [][2][]common.Address{{nil, nil}}
+	}
+	addr := []common.Address{*address}
+	return [][2][]common.Address{{addr, nil}, {nil, addr}}
+}
+
+// start 블록부터 최신 블록까지 지난 이벤트를 logRange 블록씩 나눠 블록, 로그 순서로 조회
+func (p *Model) pastEvents(ctx context.Context, client ChainBackend, filterer *cont.ContractsFilterer, address *common.Address, start uint64) ([]TokenEvent, error) {
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	toBlock := head.Number.Uint64()
+
+	events := []TokenEvent{}
+	for from := start; from <= toBlock; {
+		end := from + p.logRange - 1
+		if end > toBlock || end < from {
+			end = toBlock
+		}
+		opts := &bind.FilterOpts{Start: from, End: &end, Context: ctx}
+		for _, rule := range eventRules(address) {
+			transfers, err := filterer.FilterTransfer(opts, rule[0], rule[1])
+			if err != nil {
+				return nil, err
+			}
+			for transfers.Next() {
+				events = append(events, transferEvent(transfers.Event))
+			}
+			err = transfers.Error()
+			transfers.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			approvals, err := filterer.FilterApproval(opts, rule[0], rule[1])
+			if err != nil {
+				return nil, err
+			}
+			for approvals.Next() {
+				events = append(events, approvalEvent(approvals.Event))
+			}
+			err = approvals.Error()
+			approvals.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if end == toBlock {
+			break
+		}
+		from = end + 1
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+	return events, nil
+}
+
+func transferEvent(ev *cont.ContractsTransfer) TokenEvent {
+	e := logEvent(EventTransfer, ev.Raw)
+	e.From, e.To, e.Value = ev.From.Hex(), ev.To.Hex(), bigString(ev.Value)
+	return e
+}
+
+func approvalEvent(ev *cont.ContractsApproval) TokenEvent {
+	e := logEvent(EventApproval, ev.Raw)
+	e.Owner, e.Spender, e.Value = ev.Owner.Hex(), ev.Spender.Hex(), bigString(ev.Value)
+	return e
+}
+
+func logEvent(name string, raw types.Log) TokenEvent {
+	return TokenEvent{
+		Event:       name,
+		Token:       raw.Address.Hex(),
+		BlockNumber: raw.BlockNumber,
+		TxHash:      raw.TxHash.Hex(),
+		LogIndex:    raw.Index,
+		Removed:     raw.Removed,
+	}
+}
+
+// 이벤트를 구독할 수 있는지 확인, http rpc는 구독을 지원하지 않으므로 wsUrl이 없으면 netUrl이 ws 또는 ipc여야 함
+// 테스트에서 주입한 backend는 구독을 지원하는 것으로 봄
+func (p *Model) canSubscribe() bool {
+	if p.wsUrl != "" || !p.dialed {
+		return true
+	}
+	u, err := url.Parse(p.netUrl)
+	return err == nil && u.Scheme != "http" && u.Scheme != "https"
+}
+
+// 이벤트 구독용 backend, wsUrl이 있으면 websocket 클라이언트를 연결해 사용하고 없으면 공유 backend 사용
+func (p *Model) watchBackend() (ChainBackend, error) {
+	if p.wsUrl == "" {
+		return p.backend(), nil
+	}
+	p.wsMu.Lock()
+	defer p.wsMu.Unlock()
+
+	// 종료 후에는 다시 연결하지 않음
+	if p.isClosed() {
+		return nil, rpc.ErrClientQuit
+	}
+	if p.wsClient == nil {
+		client, err := dialClient(p.wsUrl)
+		if err != nil {
+			return nil, err
+		}
+		p.wsClient = client
+		log.Info("websocket client 연결 완료", p.wsUrl)
+	}
+	return p.wsClient, nil
+}
+
+// 구독이 끊긴 websocket 클라이언트를 닫아 다음 구독에서 다시 연결
+// wsUrl이 없으면 공유 backend와 같은 기준으로 재연결
+func (p *Model) dropWatchBackend(client ChainBackend, err error) {
+	if p.wsUrl == "" {
+		p.checkConn(err)
+		return
+	}
+	p.wsMu.Lock()
+	defer p.wsMu.Unlock()
+
+	if p.wsClient != nil && ChainBackend(p.wsClient) == client {
+		p.wsClient.Close()
+		p.wsClient = nil
+	}
+}
+
+// 서버 종료시 websocket 클라이언트 정리
+func (p *Model) closeWatchBackend() {
+	p.wsMu.Lock()
+	defer p.wsMu.Unlock()
+
+	if p.wsClient != nil {
+		p.wsClient.Close()
+		p.wsClient = nil
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEventTracker(t *testing.T) {
+	tracker := newEventTracker(&logPosition{block: 10, index: 1})
+	ev := func(block uint64, index uint) TokenEvent {
+		return TokenEvent{Event: EventTransfer, BlockNumber: block, LogIndex: index}
+	}
+
+	// cursor 위치까지의 이벤트는 이미 전달한 것으로 봄
+	if tracker.admit(ev(9, 5)) || tracker.admit(ev(10, 1)) {
+		t.Error("events before cursor admitted")
+	}
+	if !tracker.admit(ev(10, 2)) || !tracker.admit(ev(12, 0)) {
+		t.Error("events after cursor not admitted")
+	}
+	if tracker.resume != 12 {
+		t.Errorf("resume = %d, want 12", tracker.resume)
+	}
+
+	// 재구독시 다시 조회한 이벤트는 한 번만 전달
+	if tracker.admit(ev(10, 2)) || tracker.admit(ev(12, 0)) {
+		t.Error("duplicate events admitted")
+	}
+	approval := ev(12, 0)
+	approval.Event = EventApproval
+	removed := ev(12, 0)
+	removed.Removed = true
+	if !tracker.admit(approval) || !tracker.admit(removed) {
+		t.Error("distinct events not admitted")
+	}
+
+	// 기억하는 범위보다 오래된 이벤트는 무시
+	if !tracker.admit(ev(100, 0)) || tracker.admit(ev(20, 0)) {
+		t.Error("old event admitted")
+	}
+	if len(tracker.seen) != 1 {
+		t.Errorf("seen = %d, want 1", len(tracker.seen))
+	}
+}
+
+func TestCanSubscribe(t *testing.T) {
+	for _, c := range []struct {
+		netUrl, wsUrl string
+		dialed, want  bool
+	}{
+		{"http://localhost:8545", "", true, false},
+		{"https://api.test.wemix.com", "", true, false},
+		{"https://api.test.wemix.com", "wss://ws.test.wemix.com", true, true},
+		{"ws://localhost:8546", "", true, true},
+		{"/tmp/geth.ipc", "", true, true},
+		// 주입한 backend
+		{"", "", false, true},
+	} {
+		p := &Model{netUrl: c.netUrl, wsUrl: c.wsUrl, dialed: c.dialed}
+		if got := p.canSubscribe(); got != c.want {
+			t.Errorf("netUrl %q wsUrl %q canSubscribe = %v, want %v", c.netUrl, c.wsUrl, got, c.want)
+		}
+	}
+
+	p := &Model{netUrl: "http://localhost:8545", dialed: true}
+	if _, err := p.StreamTokenEventsModel(&EventQuery{}); !errors.Is(err, ErrSubscriptionUnsupported) {
+		t.Errorf("StreamTokenEventsModel err = %v, want ErrSubscriptionUnsupported", err)
+	}
+}
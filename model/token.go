@@ -0,0 +1,250 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 주소 형식이 잘못된 경우 반환
+var ErrInvalidAddress = errors.New("invalid address")
+
+// 토큰 컨트랙트 쓰기 함수, 변환된 전송량으로 생성된 바인딩 함수를 호출
+type tokenCall func(transactor *cont.ContractsTransactor, opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error)
+
+// 결과와 로그에 남길 토큰 쓰기 호출 정보
+// mint와 burn처럼 상대가 없으면 to는 zero address, sender는 transferFrom에서만 사용
+type tokenTx struct {
+	method  string
+	sender  common.Address
+	to      common.Address
+	limited bool // true면 서비스 지갑 전송 한도 적용, transferFrom은 sender가 서비스 지갑이면 적용
+}
+
+// hex 주소 문자열 검증 후 변환
+func parseAddress(name string, address string) (common.Address, error) {
+	if !common.IsHexAddress(address) {
+		return common.Address{}, fmt.Errorf("%w: %s %q", ErrInvalidAddress, name, address)
+	}
+	return common.HexToAddress(address), nil
+}
+
+// token으로 지정한 토큰 컨트랙트 조회용 바인딩
+func (p *Model) tokenCaller(token string) (*cont.ContractsCaller, *TokenInfo, error) {
+	info, err := p.resolveToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance, err := cont.NewContractsCaller(common.HexToAddress(info.Address), p.backend())
+	if err != nil {
+		log.Error("NewContractsCaller 에러", err.Error())
+		return nil, nil, err
+	}
+	return instance, info, nil
+}
+
+// name, decimals는 레지스트리에 캐시된 값 사용
+func (p *Model) SearchTokenNameModel(token string) (string, error) {
+	info, err := p.resolveToken(token)
+	if err != nil {
+		return "", err
+	}
+	return info.Name, nil
+}
+
+func (p *Model) SearchTokenDecimalsModel(token string) (uint8, error) {
+	info, err := p.resolveToken(token)
+	if err != nil {
+		return 0, err
+	}
+	return info.Decimals, nil
+}
+
+func (p *Model) SearchTokenTotalSupplyModel(token string) (*big.Int, error) {
+	instance, _, err := p.tokenCaller(token)
+	if err != nil {
+		return nil, err
+	}
+
+	totalSupply, err := instance.TotalSupply(&bind.CallOpts{})
+	if err != nil {
+		log.Error("TotalSupply 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	return totalSupply, nil
+}
+
+func (p *Model) SearchTokenAllowanceModel(token string, owner string, spender string) (*big.Int, error) {
+	ownerAddress, err := parseAddress("owner", owner)
+	if err != nil {
+		return nil, err
+	}
+	spenderAddress, err := parseAddress("spender", spender)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, _, err := p.tokenCaller(token)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance, err := instance.Allowance(&bind.CallOpts{}, ownerAddress, spenderAddress)
+	if err != nil {
+		log.Error("Allowance 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+	return allowance, nil
+}
+
+// req.TargetAddress에게 req.Amount 만큼 사용 권한 부여
+func (p *Model) ApproveTokenModel(req *SendRequest) (*SendResult, error) {
+	spender, err := parseAddress("spender", req.TargetAddress)
+	if err != nil {
+		return nil, err
+	}
+	// 승인한 spender가 서비스 지갑의 토큰을 옮길 수 있으므로 전송 대상과 같이 확인
+	if err := p.checkDestination(spender); err != nil {
+		log.Error("전송 대상 차단", err.Error())
+		return nil, err
+	}
+	// spender가 서비스 지갑의 토큰을 옮길 수 있으므로 승인량도 전송 한도 적용
+	return p.transactToken(req, tokenTx{method: "approve", to: spender, limited: true}, func(transactor *cont.ContractsTransactor, opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+		return transactor.Approve(opts, spender, value)
+	})
+}
+
+// 부여받은 사용 권한으로 sender의 토큰을 req.TargetAddress에게 전송
+func (p *Model) TransferTokenFromModel(req *SendRequest, sender string) (*SendResult, error) {
+	senderAddress, err := parseAddress("from", sender)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := parseAddress("to", req.TargetAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkDestination(recipient); err != nil {
+		log.Error("전송 대상 차단", err.Error())
+		return nil, err
+	}
+	return p.transactToken(req, tokenTx{method: "transferFrom", sender: senderAddress, to: recipient}, func(transactor *cont.ContractsTransactor, opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+		return transactor.TransferFrom(opts, senderAddress, recipient, value)
+	})
+}
+
+// 서명한 계정 앞으로 토큰 발행
+func (p *Model) MintTokenModel(req *SendRequest) (*SendResult, error) {
+	return p.transactToken(req, tokenTx{method: "mint"}, func(transactor *cont.ContractsTransactor, opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+		return transactor.Mint(opts, value)
+	})
+}
+
+// 서명한 계정의 토큰 소각
+func (p *Model) BurnTokenModel(req *SendRequest) (*SendResult, error) {
+	return p.transactToken(req, tokenTx{method: "burn"}, func(transactor *cont.ContractsTransactor, opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+		return transactor.Burn(opts, value)
+	})
+}
+
+// 토큰 컨트랙트 쓰기 호출 공통 처리
+// 전송량을 토큰 decimals 기준으로 변환한 뒤 바인딩 함수로 트랜잭션을 만들어 전송하고 결과를 기록
+func (p *Model) transactToken(req *SendRequest, info tokenTx, call tokenCall) (*SendResult, error) {
+	client := p.backend()
+	ctx := context.Background()
+
+	// req.Token으로 지정한 토큰 컨트랙트, 비어있으면 기본 토큰
+	token, err := p.resolveToken(req.Token)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddress := common.HexToAddress(token.Address)
+
+	// 전송량은 레지스트리에 캐시된 토큰의 decimals 기준으로 변환
+	value, err := ParseAmount(req.Amount, token.Decimals, req.BaseUnit)
+	if err != nil {
+		log.Error("전송량 변환 에러", err.Error())
+		return nil, err
+	}
+
+	// 기본키 지정, privatekey로부터 자신의 address 변환
+	privateKey, err := p.signingKey()
+	if err != nil {
+		return nil, err
+	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		log.Error("NetworkID 조회 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	// 생성된 바인딩 함수로 트랜잭션 생성 및 전송
+	transactor, err := cont.NewContractsTransactor(tokenAddress, client)
+	if err != nil {
+		log.Error("NewContractsTransactor 에러", err.Error())
+		return nil, err
+	}
+
+	// 서비스 지갑 전송 한도 확인, 전송하지 못하면 기록을 되돌림
+	release := func() {}
+	if info.limited || info.sender == fromAddress {
+		if release, err = p.policy.reserve(token, req.APIKey, info.to, value, time.Now()); err != nil {
+			log.Error("전송 한도 초과", err.Error())
+			return nil, err
+		}
+	}
+	signedTx, fee, err := p.transactContract(ctx, client, privateKey, chainID, &tokenAddress, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return call(transactor, opts, value)
+	})
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	//tx.hash를 이용해 전송결과를 확인
+	result := &SendResult{
+		TxHash:   signedTx.Hash().Hex(),
+		Type:     signedTx.Type(),
+		Method:   info.method,
+		Nonce:    signedTx.Nonce(),
+		From:     fromAddress.Hex(),
+		Token:    tokenAddress.Hex(),
+		Amount:   value.String(),
+		GasLimit: signedTx.Gas(),
+		ChainID:  bigString(chainID),
+	}
+	if info.sender != (common.Address{}) {
+		result.Sender = info.sender.Hex()
+	}
+	if info.to != (common.Address{}) {
+		result.To = info.to.Hex()
+	}
+	fee.fill(result)
+	log.InfoFields("token "+info.method+" sent", result.logFields()...)
+
+	// wait 옵션이 있으면 영수증을 받을 때까지 대기
+	if req.Wait != nil {
+		receipt, err := p.waitReceipt(client, signedTx, req.Wait)
+		result.Receipt = receipt
+		if err != nil {
+			log.Error("영수증 대기 에러", err.Error())
+			return result, err
+		}
+	}
+	return result, nil
+}
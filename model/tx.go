@@ -0,0 +1,206 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+
+	cont "go-contract/contracts"
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 트랜잭션 상태 값
+const (
+	TxStatusPending   = "pending"
+	TxStatusSucceeded = "succeeded"
+	TxStatusReverted  = "reverted"
+)
+
+var (
+	// 존재하지 않는 트랜잭션 조회시 반환
+	ErrTxNotFound = errors.New("transaction not found")
+	// 트랜잭션 해시 형식이 잘못된 경우 반환
+	ErrInvalidTxHash = errors.New("invalid transaction hash")
+)
+
+var txHashPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+
+// 트랜잭션 상태 및 영수증 조회 결과
+type TxStatus struct {
+	TxHash            string        `json:"txHash"`
+	Status            string        `json:"status"`
+	Mined             bool          `json:"mined"`
+	From              string        `json:"from"`
+	To                string        `json:"to"`
+	Value             string        `json:"value"`
+	Nonce             uint64        `json:"nonce"`
+	BlockNumber       string        `json:"blockNumber,omitempty"`
+	BlockHash         string        `json:"blockHash,omitempty"`
+	Confirmations     uint64        `json:"confirmations"`
+	GasUsed           uint64        `json:"gasUsed,omitempty"`
+	EffectiveGasPrice string        `json:"effectiveGasPrice,omitempty"`
+	Transfers         []TransferLog `json:"transfers"`
+}
+
+// 영수증에서 디코딩한 Transfer 이벤트
+type TransferLog struct {
+	Token    string `json:"token"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Value    string `json:"value"`
+	LogIndex uint   `json:"logIndex"`
+}
+
+func (p *Model) SearchTransactionByHashModel(hash string) (*TxStatus, error) {
+	if !txHashPattern.MatchString(hash) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTxHash, hash)
+	}
+	txHash := common.HexToHash(hash)
+	ctx := context.Background()
+
+	client := p.backend()
+
+	tx, isPending, err := client.TransactionByHash(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotFound, hash)
+	} else if err != nil {
+		log.Error("TransactionByHash 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	status := newTxStatus(tx)
+	if isPending {
+		return status, nil
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		// 블록에 포함되었지만 영수증이 아직 색인되지 않은 경우
+		return status, nil
+	} else if err != nil {
+		log.Error("TransactionReceipt 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	if err := fillReceipt(ctx, client, status, tx, receipt); err != nil {
+		p.checkConn(err)
+		return nil, err
+	}
+	return status, nil
+}
+
+// 트랜잭션 기본 정보로 pending 상태의 조회 결과 생성
+func newTxStatus(tx *types.Transaction) *TxStatus {
+	status := &TxStatus{
+		TxHash:    tx.Hash().Hex(),
+		Status:    TxStatusPending,
+		Value:     bigString(tx.Value()),
+		Nonce:     tx.Nonce(),
+		Transfers: []TransferLog{},
+	}
+	if tx.To() != nil {
+		status.To = tx.To().Hex()
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		status.From = from.Hex()
+	}
+	return status
+}
+
+// 영수증 정보로 조회 결과의 블록, 가스, 이벤트 정보를 채움
+func fillReceipt(ctx context.Context, client ChainBackend, status *TxStatus, tx *types.Transaction, receipt *types.Receipt) error {
+	status.Mined = true
+	status.Status = TxStatusReverted
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		status.Status = TxStatusSucceeded
+	}
+	status.BlockNumber = bigString(receipt.BlockNumber)
+	status.BlockHash = receipt.BlockHash.Hex()
+	status.GasUsed = receipt.GasUsed
+
+	// 확인 블록 수는 최신 블록과 포함된 블록 차이 + 1
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Error("HeaderByNumber 에러", err.Error())
+		return err
+	}
+	status.Confirmations = confirmations(head.Number, receipt.BlockNumber)
+
+	// 실제 지불된 gasPrice, dynamic fee 트랜잭션은 블록의 baseFee 기준으로 계산
+	gasPrice := tx.GasPrice()
+	if tx.Type() == types.DynamicFeeTxType {
+		block, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			log.Error("HeaderByNumber 에러", err.Error())
+			return err
+		}
+		if block.BaseFee != nil {
+			gasPrice = new(big.Int).Add(block.BaseFee, tx.GasTipCap())
+			if gasPrice.Cmp(tx.GasFeeCap()) > 0 {
+				gasPrice = tx.GasFeeCap()
+			}
+		}
+	}
+	status.EffectiveGasPrice = bigString(gasPrice)
+
+	transfers, err := decodeTransferLogs(receipt.Logs)
+	if err != nil {
+		log.Error("Transfer 로그 디코딩 에러", err.Error())
+		return err
+	}
+	status.Transfers = transfers
+	return nil
+}
+
+// 최신 블록 기준 확인 블록 수
+func confirmations(head *big.Int, block *big.Int) uint64 {
+	if head.Cmp(block) < 0 {
+		return 0
+	}
+	return new(big.Int).Sub(head, block).Uint64() + 1
+}
+
+// 영수증 로그 중 ERC-20 Transfer 이벤트만 디코딩
+func decodeTransferLogs(logs []*types.Log) ([]TransferLog, error) {
+	transferID := tokenEventID("Transfer")
+	transfers := []TransferLog{}
+	for _, l := range logs {
+		// Transfer(address indexed, address indexed, uint) 는 topic이 3개
+		if len(l.Topics) != 3 || l.Topics[0] != transferID {
+			continue
+		}
+		filterer, err := cont.NewContractsFilterer(l.Address, nil)
+		if err != nil {
+			return nil, err
+		}
+		event, err := filterer.ParseTransfer(*l)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, TransferLog{
+			Token:    l.Address.Hex(),
+			From:     event.From.Hex(),
+			To:       event.To.Hex(),
+			Value:    bigString(event.Value),
+			LogIndex: l.Index,
+		})
+	}
+	return transfers, nil
+}
+
+// 토큰 이벤트 시그니처 해시
+func tokenEventID(name string) common.Hash {
+	parsed, err := cont.ContractsMetaData.GetAbi()
+	if err != nil {
+		return common.Hash{}
+	}
+	return parsed.Events[name].ID
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "go-contract/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 영수증 대기 기본값, config에 값이 없을 때 사용
+const (
+	defaultWaitConfirmations = 1
+	defaultWaitTimeout       = 60 * time.Second
+	// 요청에서 지정할 수 있는 최대 대기 시간, config의 waitTimeout이 더 길면 waitTimeout
+	maxWaitTimeout = 300 * time.Second
+)
+
+var (
+	// 영수증 대기 중 트랜잭션이 revert 된 경우 반환
+	ErrTxReverted = errors.New("transaction reverted")
+	// 지정한 시간 안에 영수증 또는 확인 블록 수를 채우지 못한 경우 반환
+	ErrWaitTimeout = errors.New("timed out waiting for receipt")
+)
+
+// 영수증 대기 최대 시간, 서버는 이 시간보다 길게 응답 쓰기 제한 시간을 잡아 대기 결과를 응답함
+func (p *Model) MaxWaitTimeout() time.Duration {
+	if p.waitTimeout > maxWaitTimeout {
+		return p.waitTimeout
+	}
+	return maxWaitTimeout
+}
+
+// 전송한 트랜잭션이 블록에 포함되고 지정한 확인 블록 수를 채울 때까지 대기
+func (p *Model) waitReceipt(client ChainBackend, tx *types.Transaction, opt *WaitOption) (*TxStatus, error) {
+	confirms, timeout := p.waitConfirmations, p.waitTimeout
+	if opt.Confirmations > 0 {
+		confirms = opt.Confirmations
+	}
+	if opt.Timeout > 0 {
+		timeout = opt.Timeout
+	}
+	if max := p.MaxWaitTimeout(); timeout > max {
+		timeout = max
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %s 안에 블록에 포함되지 않았습니다", ErrWaitTimeout, timeout)
+		}
+		log.Error("WaitMined 에러", err.Error())
+		p.checkConn(err)
+		return nil, err
+	}
+
+	// 확인 블록 수를 채울 때까지 블록 높이를 확인
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			log.Error("HeaderByNumber 에러", err.Error())
+			p.checkConn(err)
+			return nil, err
+		}
+		if err == nil && confirmations(head.Number, receipt.BlockNumber) >= confirms {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s 안에 %d 블록 확인을 채우지 못했습니다", ErrWaitTimeout, timeout, confirms)
+		case <-ticker.C:
+		}
+	}
+
+	status := newTxStatus(tx)
+	if err := fillReceipt(ctx, client, status, tx, receipt); err != nil {
+		return nil, err
+	}
+	if status.Status == TxStatusReverted {
+		return status, fmt.Errorf("%w: %s", ErrTxReverted, status.TxHash)
+	}
+	return status, nil
+}
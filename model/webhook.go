@@ -0,0 +1,397 @@
+package model
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	conf "go-contract/config"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// webhook 설정이 없을 때 기본값
+	defaultWebhookConfirmations = 12
+	defaultWebhookPollInterval  = 5 * time.Second
+	defaultWebhookMaxAttempts   = 5
+	defaultWebhookTimeout       = 10 * time.Second
+	// 동시에 전송하는 알림 수, 나머지는 outbox에서 차례를 기다림
+	maxDeliveryWorkers = 8
+)
+
+// webhook 요청 헤더
+// 서명은 "timestamp.body"의 HMAC-SHA256을 "sha256=hex" 형식으로 보내며, delivery는 재시도와 재전송에도 같은 값
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var (
+	// 등록되지 않은 webhook id인 경우 반환
+	ErrUnknownWebhook = errors.New("unknown webhook")
+	// callback url이 잘못된 경우 반환
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+	// 없는 dead letter id인 경우 반환
+	ErrUnknownDeadLetter = errors.New("unknown dead letter")
+	// callback 전송이 실패한 경우 반환
+	ErrDeliveryFailed = errors.New("webhook delivery failed")
+)
+
+// 입금 알림을 받을 callback, Addresses로 들어오는 토큰과 코인 전송을 알림
+// Secret은 등록할 때만 응답에 포함
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Addresses []string  `json:"addresses"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// webhook 등록 요청, Secret이 비어있으면 생성
+type WebhookRequest struct {
+	URL       string
+	Addresses []string
+	Secret    string
+}
+
+// 재시도 후에도 전송하지 못한 알림, replay로 다시 전송
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	WebhookID string    `json:"webhookId"`
+	URL       string    `json:"url"`
+	Deposit   Deposit   `json:"deposit"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"lastError"`
+	FailedAt  time.Time `json:"failedAt"`
+}
+
+// 전송을 기다리는 알림, LastBlock을 갱신하기 전에 outbox에 기록해 재시작해도 잃지 않음
+// ID는 X-Webhook-Delivery 헤더 값
+type outboxDelivery struct {
+	ID        string  `json:"id"`
+	WebhookID string  `json:"webhookId"`
+	Deposit   Deposit `json:"deposit"`
+}
+
+// webhook path 파일 내용
+// LastBlock은 마지막으로 확인한 블록으로, 재시작하면 그 다음 블록부터 확인하고 Outbox에 남은 알림을 다시 전송
+type webhookState struct {
+	LastBlock   *uint64           `json:"lastBlock,omitempty"`
+	Webhooks    []*Webhook        `json:"webhooks"`
+	Outbox      []*outboxDelivery `json:"outbox"`
+	DeadLetters []*DeadLetter     `json:"deadLetters"`
+}
+
+// 등록한 webhook과 입금 확인 설정
+type webhookManager struct {
+	mu    sync.Mutex
+	path  string
+	state webhookState
+
+	confirmations uint64
+	pollInterval  time.Duration
+	maxAttempts   int
+	timeout       time.Duration
+
+	// 진행 중인 전송, 종료시 모두 끝날 때까지 대기
+	deliveries sync.WaitGroup
+	inflight   map[string]bool // 전송 중인 outbox 알림 id
+}
+
+// config의 webhook 설정을 읽고 path 파일에 기록된 상태를 불러옴
+func newWebhookManager(cfg *conf.Config) (*webhookManager, error) {
+	m := &webhookManager{path: cfg.Webhook.Path, inflight: make(map[string]bool)}
+	m.confirmations = cfg.Webhook.Confirmations
+	if m.confirmations == 0 {
+		m.confirmations = defaultWebhookConfirmations
+	}
+	m.pollInterval = time.Duration(cfg.Webhook.PollInterval) * time.Second
+	if m.pollInterval <= 0 {
+		m.pollInterval = defaultWebhookPollInterval
+	}
+	m.maxAttempts = cfg.Webhook.MaxAttempts
+	if m.maxAttempts <= 0 {
+		m.maxAttempts = defaultWebhookMaxAttempts
+	}
+	m.timeout = time.Duration(cfg.Webhook.Timeout) * time.Second
+	if m.timeout <= 0 {
+		m.timeout = defaultWebhookTimeout
+	}
+
+	if m.path != "" {
+		if err := readJSON(m.path, &m.state); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// 상태를 path 파일에 기록, 호출하는 쪽에서 mu를 잡고 있어야 함
+func (m *webhookManager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	return writeJSON(m.path, &m.state)
+}
+
+func (m *webhookManager) lastBlock() *uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state.LastBlock
+}
+
+// 마지막으로 확인한 블록 갱신, 저장에 실패하면 되돌림
+func (m *webhookManager) setLastBlock(block uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev := m.state.LastBlock
+	m.state.LastBlock = &block
+	if err := m.save(); err != nil {
+		m.state.LastBlock = prev
+		return err
+	}
+	return nil
+}
+
+// 구간에서 찾은 알림을 outbox에 기록하고 마지막으로 확인한 블록을 last로 갱신, 한 번에 저장
+func (m *webhookManager) enqueue(deliveries []*outboxDelivery, last uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prevOutbox, prevLast := m.state.Outbox, m.state.LastBlock
+	m.state.Outbox = append(m.state.Outbox[:len(m.state.Outbox):len(m.state.Outbox)], deliveries...)
+	m.state.LastBlock = &last
+	if err := m.save(); err != nil {
+		m.state.Outbox, m.state.LastBlock = prevOutbox, prevLast
+		return err
+	}
+	return nil
+}
+
+// 전송 중이 아닌 outbox 알림을 동시 전송 수 안에서 가져와 전송 중으로 표시
+func (m *webhookManager) claim() []outboxDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []outboxDelivery
+	for _, d := range m.state.Outbox {
+		if len(m.inflight) >= maxDeliveryWorkers {
+			break
+		}
+		if m.inflight[d.ID] {
+			continue
+		}
+		m.inflight[d.ID] = true
+		claimed = append(claimed, *d)
+	}
+	return claimed
+}
+
+// 전송을 끝낸 알림을 outbox에서 제거, dead가 있으면 dead letter로 함께 기록
+func (m *webhookManager) complete(id string, dead *DeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inflight, id)
+	for i, d := range m.state.Outbox {
+		if d.ID == id {
+			m.state.Outbox = append(m.state.Outbox[:i:i], m.state.Outbox[i+1:]...)
+			break
+		}
+	}
+	if dead != nil {
+		m.state.DeadLetters = append(m.state.DeadLetters, dead)
+	}
+	return m.save()
+}
+
+// 종료로 전송을 멈춘 알림은 outbox에 남겨 재시작 후 다시 전송
+func (m *webhookManager) unclaim(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inflight, id)
+}
+
+// 주소별로 알림을 받을 webhook
+func (m *webhookManager) watched() map[common.Address][]Webhook {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	watched := make(map[common.Address][]Webhook)
+	for _, hook := range m.state.Webhooks {
+		for _, addr := range hook.Addresses {
+			a := common.HexToAddress(addr)
+			watched[a] = append(watched[a], *hook)
+		}
+	}
+	return watched
+}
+
+func (m *webhookManager) webhook(id string) (Webhook, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, hook := range m.state.Webhooks {
+		if hook.ID == id {
+			return *hook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// 임의의 hex 문자열, webhook id와 secret으로 사용
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// "timestamp.body"의 HMAC-SHA256 서명
+func signWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhook 등록, 주소는 checksum 형식으로 저장하고 secret이 없으면 생성해 응답에 포함
+func (p *Model) AddWebhookModel(req *WebhookRequest) (*Webhook, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidWebhookURL, req.URL)
+	}
+	if len(req.Addresses) == 0 {
+		return nil, fmt.Errorf("%w: addresses is empty", ErrInvalidAddress)
+	}
+	hook := &Webhook{URL: req.URL, Secret: req.Secret, CreatedAt: time.Now().UTC()}
+	for _, address := range req.Addresses {
+		addr, err := parseAddress("addresses", address)
+		if err != nil {
+			return nil, err
+		}
+		hook.Addresses = append(hook.Addresses, addr.Hex())
+	}
+	if hook.ID, err = randomHex(8); err != nil {
+		return nil, err
+	}
+	if hook.Secret == "" {
+		if hook.Secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+
+	m := p.webhooks
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.Webhooks = append(m.state.Webhooks, hook)
+	if err := m.save(); err != nil {
+		m.state.Webhooks = m.state.Webhooks[:len(m.state.Webhooks)-1]
+		return nil, err
+	}
+	result := *hook
+	return &result, nil
+}
+
+// 등록된 webhook 목록, secret은 제외
+func (p *Model) ListWebhooksModel() []Webhook {
+	m := p.webhooks
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hooks := make([]Webhook, 0, len(m.state.Webhooks))
+	for _, hook := range m.state.Webhooks {
+		h := *hook
+		h.Secret = ""
+		hooks = append(hooks, h)
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].CreatedAt.Before(hooks[j].CreatedAt) })
+	return hooks
+}
+
+// webhook 삭제, 이미 쌓인 dead letter는 남겨두지만 replay할 수 없음
+func (p *Model) RemoveWebhookModel(id string) (*Webhook, error) {
+	m := p.webhooks
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, hook := range m.state.Webhooks {
+		if hook.ID != id {
+			continue
+		}
+		m.state.Webhooks = append(m.state.Webhooks[:i:i], m.state.Webhooks[i+1:]...)
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+		result := *hook
+		result.Secret = ""
+		return &result, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownWebhook, id)
+}
+
+// 전송하지 못한 알림 목록, 실패한 순서
+func (p *Model) ListDeadLettersModel() []DeadLetter {
+	m := p.webhooks
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	letters := make([]DeadLetter, 0, len(m.state.DeadLetters))
+	for _, d := range m.state.DeadLetters {
+		letters = append(letters, *d)
+	}
+	return letters
+}
+
+// dead letter를 현재 webhook 설정으로 한 번 다시 전송, 성공하면 목록에서 제거
+func (p *Model) ReplayDeadLetterModel(id string) (*DeadLetter, error) {
+	m := p.webhooks
+	m.mu.Lock()
+	var letter *DeadLetter
+	for _, d := range m.state.DeadLetters {
+		if d.ID == id {
+			letter = d
+		}
+	}
+	m.mu.Unlock()
+	if letter == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDeadLetter, id)
+	}
+	hook, ok := m.webhook(letter.WebhookID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWebhook, letter.WebhookID)
+	}
+
+	err := p.postWebhook(context.Background(), hook, letter.Deposit)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, d := range m.state.DeadLetters {
+		if d != letter {
+			continue
+		}
+		if err == nil {
+			m.state.DeadLetters = append(m.state.DeadLetters[:i:i], m.state.DeadLetters[i+1:]...)
+		} else {
+			d.Attempts++
+			d.LastError = err.Error()
+			d.FailedAt = time.Now().UTC()
+		}
+		break
+	}
+	result := *letter
+	if saveErr := m.save(); saveErr != nil && err == nil {
+		return &result, saveErr
+	}
+	if err != nil {
+		return &result, fmt.Errorf("%w: %v", ErrDeliveryFailed, err)
+	}
+	return &result, nil
+}
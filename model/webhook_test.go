@@ -0,0 +1,125 @@
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	conf "go-contract/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// echo -n '1700000000.{"id":"1"}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54"
+	if got := signWebhook("secret", "1700000000", []byte(`{"id":"1"}`)); got != want {
+		t.Errorf("signWebhook = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookManagerState(t *testing.T) {
+	cfg := new(conf.Config)
+	cfg.Webhook.Path = filepath.Join(t.TempDir(), "webhook.json")
+	m, err := newWebhookManager(cfg)
+	if err != nil {
+		t.Fatalf("newWebhookManager Error: %s", err)
+	}
+	if m.confirmations != defaultWebhookConfirmations || m.maxAttempts != defaultWebhookMaxAttempts || m.lastBlock() != nil {
+		t.Errorf("defaults = %+v", m)
+	}
+
+	p := &Model{webhooks: m}
+	hook, err := p.AddWebhookModel(&WebhookRequest{URL: "https://example.com/hook", Addresses: []string{"0x000000000000000000000000000000000000dEaD"}})
+	if err != nil || len(hook.Secret) != 64 {
+		t.Fatalf("AddWebhookModel = %+v, %v", hook, err)
+	}
+	m.setLastBlock(10)
+	m.complete("unknown", &DeadLetter{ID: "d1", WebhookID: hook.ID})
+
+	m, err = newWebhookManager(cfg)
+	if err != nil {
+		t.Fatalf("newWebhookManager Error: %s", err)
+	}
+	if last := m.lastBlock(); last == nil || *last != 10 {
+		t.Errorf("lastBlock = %v, want 10", last)
+	}
+	if hooks := m.watched(); len(hooks) != 1 || len(m.state.DeadLetters) != 1 {
+		t.Errorf("state = %+v", m.state)
+	}
+}
+
+func TestWebhookOutbox(t *testing.T) {
+	cfg := new(conf.Config)
+	cfg.Webhook.Path = filepath.Join(t.TempDir(), "webhook.json")
+	m, err := newWebhookManager(cfg)
+	if err != nil {
+		t.Fatalf("newWebhookManager Error: %s", err)
+	}
+	var deliveries []*outboxDelivery
+	for i := 0; i < maxDeliveryWorkers+2; i++ {
+		id := strconv.Itoa(i)
+		deliveries = append(deliveries, &outboxDelivery{ID: "h:" + id, WebhookID: "h", Deposit: Deposit{ID: id}})
+	}
+	if err := m.enqueue(deliveries, 20); err != nil {
+		t.Fatalf("enqueue Error: %s", err)
+	}
+
+	// 동시 전송 수까지만 가져오고, 끝난 자리만큼 다음 알림을 가져옴
+	claimed := m.claim()
+	if len(claimed) != maxDeliveryWorkers || len(m.claim()) != 0 {
+		t.Fatalf("claimed = %d, want %d", len(claimed), maxDeliveryWorkers)
+	}
+	m.complete(claimed[0].ID, nil)
+	m.complete(claimed[1].ID, &DeadLetter{ID: "d1", WebhookID: "h"})
+	m.unclaim(claimed[2].ID)
+	if next := m.claim(); len(next) != 3 || next[0].ID != claimed[2].ID {
+		t.Errorf("next claim = %+v", next)
+	}
+
+	// 재시작하면 전송하지 못한 알림이 outbox에 남아 있음
+	m, err = newWebhookManager(cfg)
+	if err != nil {
+		t.Fatalf("newWebhookManager Error: %s", err)
+	}
+	if last := m.lastBlock(); last == nil || *last != 20 {
+		t.Errorf("lastBlock = %v, want 20", last)
+	}
+	if len(m.state.Outbox) != maxDeliveryWorkers || len(m.state.DeadLetters) != 1 || len(m.claim()) != maxDeliveryWorkers {
+		t.Errorf("state = %+v", m.state)
+	}
+}
+
+// 등록된 webhook이 없어도 확인한 블록을 저장해 재시작 후 지난 입금을 보내지 않음
+func TestScanDepositsWithoutWebhooks(t *testing.T) {
+	sim := &simBackend{backends.NewSimulatedBackend(core.GenesisAlloc{}, 30000000)}
+	defer sim.Close()
+	cfg := new(conf.Config)
+	cfg.Webhook.Path = filepath.Join(t.TempDir(), "webhook.json")
+	cfg.Webhook.Confirmations = 1
+	m, err := newWebhookManager(cfg)
+	if err != nil {
+		t.Fatalf("newWebhookManager Error: %s", err)
+	}
+	p := &Model{webhooks: m, client: sim}
+
+	ctx := context.Background()
+	if err := p.scanDeposits(ctx); err != nil {
+		t.Fatalf("scanDeposits Error: %s", err)
+	}
+	sim.Commit()
+	sim.Commit()
+	if err := p.scanDeposits(ctx); err != nil {
+		t.Fatalf("scanDeposits Error: %s", err)
+	}
+
+	m, err = newWebhookManager(cfg)
+	if err != nil {
+		t.Fatalf("newWebhookManager Error: %s", err)
+	}
+	if last := m.lastBlock(); last == nil || *last != 2 {
+		t.Errorf("lastBlock = %v, want 2", last)
+	}
+}
@@ -1,11 +1,14 @@
 package router
 
 import (
-	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	ctl "go-contract/controller"
 	"go-contract/docs"
 	"go-contract/logger"
+	md "go-contract/model"
 
 	"github.com/gin-gonic/gin"
 	swgFiles "github.com/swaggo/files"
@@ -27,7 +30,7 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Forwarded-For, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-Forwarded-For, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, X-Signature, X-Timestamp, X-Nonce")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -37,17 +40,32 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// 임의 인증을 위한 함수
-func liteAuth() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if c == nil {
-			c.Abort()
-			return
+// 이벤트 스트리밍 경로, 구독하는 동안 계속 응답을 쓰므로 쓰기 제한 시간을 두지 않음
+const eventStreamPath = "/v1/token/events/"
+
+// 응답 쓰기 제한 시간을 넘은 경우 응답
+const writeTimeoutBody = `{"message":"응답 시간이 초과되었습니다!","error":"http: Handler timeout"}`
+
+// 응답 쓰기 제한 시간을 경로별로 적용한 handler
+// POST, DELETE 요청은 영수증 대기(wait=true, 배포)를 포함할 수 있으므로 waitTimeout, 나머지는 timeout
+// 이벤트 스트리밍은 제한 시간을 두지 않으므로 http.Server의 WriteTimeout 대신 사용
+func (p *Router) Handler(timeout time.Duration, waitTimeout time.Duration) http.Handler {
+	return withWriteTimeout(p.Idx(), timeout, waitTimeout)
+}
+
+func withWriteTimeout(h http.Handler, timeout time.Duration, waitTimeout time.Duration) http.Handler {
+	short := http.TimeoutHandler(h, timeout, writeTimeoutBody)
+	long := http.TimeoutHandler(h, waitTimeout, writeTimeoutBody)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, eventStreamPath):
+			h.ServeHTTP(w, r)
+		case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+			short.ServeHTTP(w, r)
+		default:
+			long.ServeHTTP(w, r)
 		}
-		auth := c.GetHeader("Authorization")
-		fmt.Println("Authorization-word", auth)
-		c.Next()
-	}
+	})
 }
 
 // 실제 라우팅
@@ -62,21 +80,98 @@ func (p *Router) Idx() *gin.Engine {
 	e.GET("/swagger/:any", ginSwg.WrapHandler(swgFiles.Handler))
 	docs.SwaggerInfo.Host = "localhost:8080"
 
-	version1 := e.Group("v1", liteAuth())
+	// API key 인증과 요청 서명 확인 후 경로별 scope 확인
+	version1 := e.Group("v1", p.ct.Authenticate(), p.ct.VerifySignature())
 	{
+		tokenRead := p.ct.RequireScope(md.ScopeTokenRead)
+		tokenSend := p.ct.RequireScope(md.ScopeTokenSend)
+		coinRead := p.ct.RequireScope(md.ScopeCoinRead)
+		coinSend := p.ct.RequireScope(md.ScopeCoinSend)
+		admin := p.ct.RequireScope(md.ScopeAdmin)
+
 		token := version1.Group("token")
 		{
-			token.POST("/", p.ct.SendTokenByAddressController)
+			token.POST("/", tokenSend, p.ct.SendTokenByAddressController)
+
+			token.GET("/symbol", tokenRead, p.ct.SearchTokenSymbolByTokenNameController)
+			token.GET("/balance", tokenRead, p.ct.SearchTokenBalanceByAddressController)
+
+			token.GET("/name", tokenRead, p.ct.SearchTokenNameController)
+			token.GET("/decimals", tokenRead, p.ct.SearchTokenDecimalsController)
+			token.GET("/totalSupply", tokenRead, p.ct.SearchTokenTotalSupplyController)
+			token.GET("/allowance", tokenRead, p.ct.SearchTokenAllowanceController)
+			token.POST("/approve", tokenSend, p.ct.ApproveTokenController)
+			token.POST("/transferFrom", tokenSend, p.ct.TransferTokenFromController)
+			token.POST("/mint", admin, p.ct.MintTokenController)
+			token.POST("/burn", admin, p.ct.BurnTokenController)
+			token.POST("/build", tokenSend, p.ct.BuildTokenTxController)
+			token.GET("/transfers", tokenRead, p.ct.SearchTransfersController)
+			token.GET("/holders", tokenRead, p.ct.SearchTokenHoldersController)
+			token.POST("/events/ticket", tokenRead, p.ct.IssueStreamTicketController)
+		}
+
+		// 이벤트 구독, 헤더를 설정할 수 없는 브라우저를 위해 API key 대신 ticket 쿼리로도 인증
+		events := e.Group(eventStreamPath, p.ct.AuthenticateStream(), tokenRead)
+		{
+			events.GET("/sse", p.ct.StreamTokenEventsSSEController)
+			events.GET("/ws", p.ct.StreamTokenEventsWSController)
+		}
+
+		// 토큰 레지스트리, token 파라미터로 사용할 토큰을 주소 또는 심볼로 지정
+		tokens := version1.Group("tokens")
+		{
+			tokens.GET("/", tokenRead, p.ct.ListTokensController)
+			tokens.POST("/", admin, p.ct.AddTokenController)
+			tokens.GET("/:token", tokenRead, p.ct.SearchTokenInfoController)
+			tokens.DELETE("/:token", admin, p.ct.RemoveTokenController)
+		}
+
+		// 입금 알림 webhook, 재시도 후에도 실패한 알림은 deadletters에서 조회하고 다시 전송
+		webhooks := version1.Group("webhooks", admin)
+		{
+			webhooks.GET("/", p.ct.ListWebhooksController)
+			webhooks.POST("/", p.ct.AddWebhookController)
+			webhooks.DELETE("/:id", p.ct.RemoveWebhookController)
+			webhooks.GET("/deadletters", p.ct.ListDeadLettersController)
+			webhooks.POST("/deadletters/:id/replay", p.ct.ReplayDeadLetterController)
+		}
+
+		version1.GET("/indexer", tokenRead, p.ct.IndexerStatusController)
+		version1.GET("/account", coinRead, p.ct.SearchAccountController)
 
-			token.GET("/symbol", p.ct.SearchTokenSymbolByTokenNameController)
-			token.GET("/balance", p.ct.SearchTokenBalanceByAddressController)
-			token.POST("/private", p.ct.SendTokenByAddressWithPrivateKeyController)
+		contracts := version1.Group("contracts", admin)
+		{
+			contracts.POST("/deploy", p.ct.DeployContractController)
 		}
 
 		coin := version1.Group("coin")
 		{
-			coin.POST("/", p.ct.SendWemixCoinByAddressController)
-			coin.POST("/private", p.ct.SendWemixCoinByAddressWithPrivateKeyController)
+			coin.POST("/", coinSend, p.ct.SendWemixCoinByAddressController)
+			coin.GET("/balance", coinRead, p.ct.SearchCoinBalanceController)
+			coin.POST("/build", coinSend, p.ct.BuildCoinTxController)
+		}
+
+		tx := version1.Group("tx")
+		{
+			tx.GET("/:hash", p.ct.RequireScope(md.ScopeTokenRead, md.ScopeCoinRead), p.ct.SearchTransactionByHashController)
+			// 서명된 트랜잭션은 토큰, 코인 전송 모두 가능
+			tx.POST("/broadcast", p.ct.RequireScope(md.ScopeTokenSend, md.ScopeCoinSend), p.ct.BroadcastTxController)
+		}
+
+		// API key 관리, 발급한 key는 응답에서 한 번만 확인 가능
+		apikeys := version1.Group("apikeys", admin)
+		{
+			apikeys.GET("/", p.ct.ListAPIKeysController)
+			apikeys.POST("/", p.ct.IssueAPIKeyController)
+			apikeys.DELETE("/:id", p.ct.RevokeAPIKeyController)
+		}
+
+		// 서비스 지갑 전송 대상 주소 제한, deny 목록과 allowlistOnly면 allow 목록으로 확인
+		addresses := version1.Group("addresses", admin)
+		{
+			addresses.GET("/", p.ct.ListAddressRulesController)
+			addresses.POST("/", p.ct.AddAddressRuleController)
+			addresses.DELETE("/:list/:address", p.ct.RemoveAddressRuleController)
 		}
 	}
 
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// delay만큼 기다린 뒤 응답하는 handler, 이벤트 스트리밍 경로는 이벤트를 flush하며 delay 뒤에 한 번 더 보냄
+func slowHandler(delay time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, eventStreamPath) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data:first\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			io.WriteString(w, "data:second\n\n")
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		io.WriteString(w, `{"message":"ok"}`)
+	})
+}
+
+func TestWriteTimeout(t *testing.T) {
+	srv := httptest.NewServer(withWriteTimeout(slowHandler(300*time.Millisecond), 50*time.Millisecond, 5*time.Second))
+	defer srv.Close()
+
+	// 조회 요청은 timeout을 넘으면 503
+	res, err := http.Get(srv.URL + "/v1/token/name")
+	if err != nil {
+		t.Fatalf("GET Error: %s", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable || string(body) != writeTimeoutBody {
+		t.Errorf("slow GET status = %d, body: %s", res.StatusCode, body)
+	}
+
+	// 영수증을 기다리는 요청은 waitTimeout까지 기다림
+	res, err = http.Post(srv.URL+"/v1/token/?wait=true", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST Error: %s", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != `{"message":"ok"}` {
+		t.Errorf("wait POST status = %d, body: %s", res.StatusCode, body)
+	}
+}
+
+func TestWriteTimeoutEventStream(t *testing.T) {
+	srv := httptest.NewServer(withWriteTimeout(slowHandler(300*time.Millisecond), 50*time.Millisecond, 100*time.Millisecond))
+	defer srv.Close()
+
+	start := time.Now()
+	res, err := http.Get(srv.URL + eventStreamPath + "sse")
+	if err != nil {
+		t.Fatalf("GET Error: %s", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("sse status = %d, content-type = %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	// 첫 이벤트는 응답이 끝나기 전에 바로 전달되고, timeout이 지나도 스트림이 끊기지 않음
+	reader := bufio.NewReader(res.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "data:first\n" {
+		t.Fatalf("first line = %q, err = %v", line, err)
+	}
+	if elapsed := time.Since(start); elapsed >= 300*time.Millisecond {
+		t.Errorf("first event after %s, want flushed before the handler returns", elapsed)
+	}
+	rest, _ := io.ReadAll(reader)
+	if string(rest) != "\ndata:second\n\n" {
+		t.Errorf("rest = %q", rest)
+	}
+}
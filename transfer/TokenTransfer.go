@@ -8,11 +8,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"golang.org/x/crypto/sha3"
 
 	"go-contract/contracts" // 자신의 경로에 맞게 수정
 )
@@ -74,62 +71,29 @@ func TransferCtxCoz() {
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
-	// 현재 계정의 nonce를 가져옴. 다음 트랜잭션에서 사용할 nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
-	if err != nil {
-		fmt.Println(err)
-	}
-
-	// 전송할 양, gasLimit, gasPrice 설정. 추천되는 gasPrice를 가져옴
+	// 전송할 양
 	value := big.NewInt(700000000000000000)
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	if err != nil {
-		fmt.Println(err)
-	}
 
 	// 보낼 주소
 	toAddress := common.HexToAddress("0x5D86dE4B82091dBF1fd2c706d36ebC98E3d4d5Cd")
 
-	// 컨트랙트 전송시 사용할 함수명
-	transferFnSignature := []byte("transfer(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-	fmt.Println(hexutil.Encode(methodID))
-
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
-	fmt.Println(hexutil.Encode(paddedAddress)) // 0x0000000000000000000000004592d8f8d7b001e72cb26a73e4fa1806a51ac79d
-
-	paddedAmount := common.LeftPadBytes(value.Bytes(), 32)
-	fmt.Println(hexutil.Encode(paddedAmount)) // 0x00000000000000000000000000000000000000000000003635c9adc5dea00000
-	zvalue := big.NewInt(0)
-
-	//컨트랙트 전송 정보 입력
-	var pdata []byte
-	pdata = append(pdata, methodID...)
-	pdata = append(pdata, paddedAddress...)
-	pdata = append(pdata, paddedAmount...)
-
-	gasLimit := uint64(200000)
-	fmt.Println(gasLimit)
-
-	// 트랜잭션 생성
-	tx := types.NewTransaction(nonce, tokenAddress, zvalue, gasLimit, gasPrice, pdata)
 	chainID, err := client.NetworkID(context.Background())
 	if err != nil {
 		fmt.Println(err)
 	}
 
-	// 트랜잭션 서명
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	// 서명에 사용할 TransactOpts 생성. nonce, gasPrice, gasLimit은 바인딩이 채워줌
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
 	if err != nil {
 		fmt.Println(err)
 	}
+	fmt.Printf("from: %s\n", fromAddress.Hex())
 
-	// 트랜잭션 전송
-	err = client.SendTransaction(context.Background(), signedTx)
+	// 생성된 바인딩의 transfer(address,uint256)로 트랜잭션 생성, 서명 및 전송
+	signedTx, err := instance.Transfer(auth, toAddress, value)
 	if err != nil {
 		fmt.Println(err)
+		return
 	}
 
 	//tx.hash를 이용해 전송결과를 확인